@@ -0,0 +1,202 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type adaptiveOption struct {
+	extension time.Duration
+	max       time.Duration
+}
+
+// AdaptivePeriod returns an Option that allows the lame-duck period to be
+// extended while requests are still draining. The configured Period serves as
+// the base period. When the lame-duck deadline arrives, it is extended by the
+// given extension if the number of in-flight requests has dropped since lame-
+// duck mode began (or since the previous extension) but is not yet zero; this
+// repeats until the in-flight count stops dropping or the lame-duck period
+// reaches max, which must be no less than the base Period.
+//
+// In-flight requests are those tracked by the Runner's ConnRegistry: all
+// registered connections plus any keep-alive connections (see
+// ConnRegistry.ConnState) that are active.
+//
+// The deadline of the Context passed to the Server's Shutdown method (and the
+// pre-shutdown hook) is adjusted accordingly. Each extension is logged and
+// recorded in the Runner's Report.
+func AdaptivePeriod(extension, max time.Duration) Option {
+	return &adaptiveOption{extension, max}
+}
+
+func (o *adaptiveOption) set(r *Runner) {
+	r.adaptive = o
+}
+
+func (o *adaptiveOption) validate(period time.Duration) error {
+	if o.extension <= 0 {
+		return errors.New("lame-duck extension must be greater than zero")
+	}
+
+	if o.max < period {
+		return errors.New("maximum lame-duck period must not be less than the base period")
+	}
+
+	return nil
+}
+
+// Extension describes a single extension of the lame-duck period; see the
+// AdaptivePeriod Option.
+type Extension struct {
+	At       time.Time // When the extension was granted
+	InFlight int       // The number of in-flight requests at that time
+	Deadline time.Time // The new lame-duck deadline
+}
+
+// inFlight returns the number of in-flight requests tracked by the receiver.
+func (cr *ConnRegistry) inFlight() int {
+	if cr == nil {
+		return 0
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	n := len(cr.conns)
+
+	for _, s := range cr.keepalive {
+		if s == http.StateNew || s == http.StateActive {
+			n++
+		}
+	}
+
+	return n
+}
+
+// extender returns a function, suitable for use with newExtendableContext,
+// that decides whether to extend a lame-duck deadline that has arrived. The
+// lame-duck period will not be extended beyond limit.
+func (r *Runner) extender(limit time.Time) func(time.Time) (time.Time, bool) {
+	last := r.conns.inFlight()
+
+	return func(deadline time.Time) (time.Time, bool) {
+		n := r.conns.inFlight()
+		prev := last
+		last = n
+
+		if n == 0 || n >= prev || !deadline.Before(limit) {
+			return deadline, false
+		}
+
+		next := deadline.Add(r.adaptive.extension)
+		if next.After(limit) {
+			next = limit
+		}
+
+		r.logInfo("Extending lame-duck period", "in_flight", n, "previous", prev, "deadline", next)
+
+		r.updateReport(func(rp *Report) {
+			rp.Deadline = next
+			rp.Extensions = append(rp.Extensions, Extension{At: time.Now(), InFlight: n, Deadline: next})
+		})
+
+		return next, true
+	}
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// extendableContext is a Context whose deadline may be extended. When its
+// deadline arrives, its extend function is consulted and, should it return
+// a new deadline, the Context remains active until then.
+type extendableContext struct {
+	context.Context // parent
+
+	extend func(time.Time) (time.Time, bool)
+
+	mu       sync.Mutex
+	deadline time.Time
+	timer    *time.Timer
+	done     chan struct{}
+	err      error
+}
+
+func newExtendableContext(parent context.Context, deadline time.Time, extend func(time.Time) (time.Time, bool)) (context.Context, context.CancelFunc) {
+	c := &extendableContext{
+		Context:  parent,
+		extend:   extend,
+		deadline: deadline,
+		done:     make(chan struct{}),
+	}
+
+	c.mu.Lock()
+	c.timer = time.AfterFunc(time.Until(deadline), c.expire)
+	c.mu.Unlock()
+
+	go func() {
+		select {
+		case <-parent.Done():
+			c.cancel(parent.Err())
+		case <-c.done:
+		}
+	}()
+
+	return c, func() { c.cancel(context.Canceled) }
+}
+
+func (c *extendableContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.deadline, true
+}
+
+func (c *extendableContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *extendableContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// expire is called when the receiver's deadline arrives.
+func (c *extendableContext) expire() {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return
+	}
+	deadline := c.deadline
+	c.mu.Unlock()
+
+	if next, ok := c.extend(deadline); ok {
+		c.mu.Lock()
+		if c.err == nil {
+			c.deadline = next
+			c.timer.Reset(time.Until(next))
+		}
+		c.mu.Unlock()
+		return
+	}
+
+	c.cancel(context.DeadlineExceeded)
+}
+
+func (c *extendableContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return
+	}
+
+	c.err = err
+	c.timer.Stop()
+	close(c.done)
+}
@@ -0,0 +1,92 @@
+package lameduck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestAdaptivePeriod(t *testing.T) {
+	cases := map[string]struct {
+		releases   []time.Duration // when each tracked connection is released
+		want       *LameDuckError
+		extensions int
+	}{
+		"draining": {
+			releases:   []time.Duration{20 * time.Millisecond, 70 * time.Millisecond, 120 * time.Millisecond},
+			extensions: 2,
+		},
+		"stalled": {
+			releases:   []time.Duration{20 * time.Millisecond, time.Minute, time.Minute},
+			want:       &LameDuckError{Expired: true},
+			extensions: 1,
+		},
+		"limited": {
+			releases:   []time.Duration{20 * time.Millisecond, 70 * time.Millisecond, 120 * time.Millisecond, 170 * time.Millisecond, time.Minute},
+			want:       &LameDuckError{Expired: true},
+			extensions: 2,
+		},
+	}
+
+	for label, tc := range cases {
+		tc := tc
+		t.Run(label, func(t *testing.T) {
+			ts := injectSignaller()
+			defer ts.revert()
+
+			tl := &testLogger{t.Logf}
+			svr := newTestServer(tl, nil, nil, nil)
+			svr.shutdown.finish()
+
+			r, err := NewRunner(svr, WithLogger(tl), Period(50*time.Millisecond), AdaptivePeriod(50*time.Millisecond, 150*time.Millisecond))
+			if err != nil {
+				t.Fatalf("cannot create Runner: %v", err)
+			}
+
+			var releases []func()
+			for range tc.releases {
+				releases = append(releases, r.Conns().Register(closerFunc(func() error { return nil }), nil))
+			}
+
+			errs := make(chan error, 1)
+			go func() { errs <- r.Run(context.Background()) }()
+
+			<-r.Ready()
+			time.Sleep(10 * time.Millisecond)
+			ts.emit(unix.SIGTERM)
+			<-r.LameDuck()
+
+			for i, d := range tc.releases {
+				t := time.AfterFunc(d, releases[i])
+				defer t.Stop()
+			}
+
+			if got := <-errs; !tc.want.isEqual(got) {
+				t.Errorf("Run(ctx) == %#v; wanted %#v", got, tc.want)
+			}
+
+			rp := r.LastReport()
+
+			if got := len(rp.Extensions); got != tc.extensions {
+				t.Errorf("len(Report.Extensions) == %d; wanted %d", got, tc.extensions)
+			}
+
+			if n := len(rp.Extensions); n > 0 && !rp.Deadline.Equal(rp.Extensions[n-1].Deadline) {
+				t.Errorf("Report.Deadline == %v; wanted %v", rp.Deadline, rp.Extensions[n-1].Deadline)
+			}
+		})
+	}
+}
+
+func TestAdaptivePeriodValidation(t *testing.T) {
+	for _, o := range []Option{
+		AdaptivePeriod(0, time.Minute),
+		AdaptivePeriod(time.Second, time.Millisecond),
+	} {
+		if _, err := NewRunner(newTestServer(nil, nil, nil, nil), o); err == nil {
+			t.Errorf("NewRunner(%+v) returned nil error", o)
+		}
+	}
+}
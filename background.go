@@ -0,0 +1,148 @@
+package lameduck
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// background holds the per-run state for goroutines started with Runner.Go.
+type background struct {
+	r      *Runner
+	eg     *errgroup.Group
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	close  sync.Once
+}
+
+// Go runs f in its own goroutine as part of the receiver's lifecycle. This is
+// intended for helper goroutines (cache refreshers, pollers, etc.) that should
+// stop when the receiver's Server stops.
+//
+// The Context passed to f is canceled when lame-duck mode begins and the
+// Runner will wait (for the remainder of the lame-duck period) for f to
+// return before completing lame-duck mode. Should f fail to return in time,
+// the lame-duck period is considered expired. Note however that Run will not
+// return until f has returned, so f must honor its Context.
+//
+// If f returns a non-nil error (other than its Context's error, after
+// cancellation) that error is treated as if it were returned by Serve; the
+// Server is closed (since its Serve method may not observe its Context) and
+// Run will return a LameDuckError with its Failed field set to true.
+//
+// If Go is called while Run is executing, f is started immediately.
+// Otherwise, f is started each time Run is called.
+func (r *Runner) Go(f func(ctx context.Context) error) {
+	if r == nil || f == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.bg == nil {
+		r.bgFuncs = append(r.bgFuncs, f)
+		return
+	}
+
+	r.bg.start(f)
+}
+
+// startBackground establishes the background state for a new run (using
+// the given errgroup and Context) and starts all pending goroutines.
+func (r *Runner) startBackground(ctx context.Context, eg *errgroup.Group) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bg := &background{r: r, eg: eg}
+	bg.ctx, bg.cancel = context.WithCancel(ctx)
+
+	for _, f := range r.bgFuncs {
+		bg.start(f)
+	}
+
+	r.bg = bg
+}
+
+// stopBackground cancels the Context for all background goroutines.
+func (r *Runner) stopBackground() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.bg != nil {
+		r.bg.cancel()
+	}
+}
+
+// waitBackground waits for all background goroutines to return or for ctx to
+// be done, in which case ctx.Err() is returned.
+func (r *Runner) waitBackground(ctx context.Context) error {
+	r.mu.Lock()
+	bg := r.bg
+	r.mu.Unlock()
+
+	if bg == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		bg.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-done:
+		return nil
+	}
+}
+
+// endBackground discards the per-run background state.
+func (r *Runner) endBackground() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.bg != nil {
+		r.bg.cancel()
+		r.bg = nil
+	}
+}
+
+func (bg *background) start(f func(context.Context) error) {
+	bg.wg.Add(1)
+
+	bg.eg.Go(func() error {
+		defer bg.wg.Done()
+
+		err := f(bg.ctx)
+
+		switch {
+		case err == nil:
+			return nil
+
+		case bg.ctx.Err() != nil && err == bg.ctx.Err():
+			return nil
+
+		default:
+			bg.fail(err)
+			return &LameDuckError{Failed: true, Err: err}
+		}
+	})
+}
+
+// fail closes the Server after a background goroutine has failed. Since the
+// run is then aborted without calling Shutdown, this is the only way to stop
+// a Server whose Serve method ignores its Context.
+func (bg *background) fail(err error) {
+	bg.close.Do(func() {
+		bg.r.logError("Background goroutine failed; closing server", "error", err)
+		if err := bg.r.server.Close(); err != nil {
+			bg.r.logWarn("Error closing server", "error", err)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// deafServer is a testServer whose Serve method ignores its Context, as does
+// one wrapping http.Server's ListenAndServe.
+type deafServer struct {
+	*testServer
+}
+
+func (ds *deafServer) Serve(context.Context) error {
+	return ds.testServer.Serve(context.Background())
+}
+
+func TestGo(t *testing.T) {
+	errHelper := errors.New("helper failed")
+
+	cases := map[string]struct {
+		helper func(context.Context) error
+		signal bool
+		want   *LameDuckError
+	}{
+		"canceled": {
+			helper: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() },
+			signal: true,
+		},
+		"failed": {
+			helper: func(context.Context) error { return errHelper },
+			want:   &LameDuckError{Failed: true, Err: errHelper},
+		},
+		"expired": {
+			helper: func(ctx context.Context) error { <-ctx.Done(); time.Sleep(50 * time.Millisecond); return nil },
+			signal: true,
+			want:   &LameDuckError{Expired: true},
+		},
+	}
+
+	for label, tc := range cases {
+		tc := tc
+		t.Run(label, func(t *testing.T) {
+			ts := injectSignaller()
+			defer ts.revert()
+
+			tl := &testLogger{t.Logf}
+			svr := &deafServer{newTestServer(tl, nil, nil, nil)}
+			svr.shutdown.finish()
+
+			r, err := NewRunner(svr, WithLogger(tl), Period(20*time.Millisecond))
+			if err != nil {
+				t.Fatalf("cannot create Runner: %v", err)
+			}
+
+			r.Go(tc.helper)
+
+			errs := make(chan error, 1)
+			go func() { errs <- r.Run(context.Background()) }()
+
+			if tc.signal {
+				<-r.Ready()
+				time.Sleep(10 * time.Millisecond)
+				ts.emit(unix.SIGTERM)
+			}
+
+			select {
+			case got := <-errs:
+				if !tc.want.isEqual(got) {
+					t.Errorf("Run(ctx) == %#v; wanted %#v", got, tc.want)
+				}
+
+			case <-time.After(time.Second):
+				t.Fatal("Run(ctx) did not return")
+			}
+		})
+	}
+}
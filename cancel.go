@@ -0,0 +1,46 @@
+package lameduck
+
+import (
+	"context"
+	"time"
+)
+
+// ShutdownOnCancel returns an Option that treats cancellation of the Context
+// passed to Run as a lame-duck trigger. Ordinarily, should this Context be
+// canceled, Run returns immediately without calling the Server's Shutdown
+// method. With this Option, the Server is instead shut down exactly as if a
+// signal had been received, using a fresh deadline derived from the lame-duck
+// Period (and DrainDelay, if any).
+//
+// Since the Server is run using a Context detached from the one passed to Run,
+// its Serve method no longer observes that Context's cancellation directly;
+// the Context's values, however, remain available.
+//
+// Run returns a LameDuckError with its Canceled field set to true. Should
+// lame-duck mode complete successfully, its Err field is nil and OutcomeOf
+// reports it as Clean (so Main exits with the Clean exit code). The error of
+// the Context passed to Run is never reported in Err.
+func ShutdownOnCancel() Option {
+	return new(shutdownOnCancel)
+}
+
+type shutdownOnCancel struct{}
+
+func (*shutdownOnCancel) set(r *Runner) {
+	r.cancelTrigger = true
+}
+
+// cancelTrigger is reported when lame-duck mode is triggered by cancellation
+// of Run's Context (see ShutdownOnCancel).
+const cancelTrigger = manualTrigger("cancel")
+
+// detachedContext carries the values of its parent Context but is never
+// canceled and has no deadline.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)          { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}                { return nil }
+func (detachedContext) Err() error                           { return nil }
+func (dc detachedContext) Value(key interface{}) interface{} { return dc.parent.Value(key) }
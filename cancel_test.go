@@ -0,0 +1,68 @@
+package lameduck
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestShutdownOnCancel(t *testing.T) {
+	cases := map[string]struct {
+		finish  bool
+		want    *LameDuckError
+		outcome Outcome
+	}{
+		"clean":   {finish: true, want: &LameDuckError{}, outcome: Clean},
+		"expired": {want: &LameDuckError{Expired: true}, outcome: Expired},
+	}
+
+	for label, tc := range cases {
+		tc := tc
+		t.Run(label, func(t *testing.T) {
+			tl := &testLogger{t.Logf}
+			svr := newTestServer(tl, nil, nil, nil)
+			if tc.finish {
+				svr.shutdown.finish()
+			}
+
+			r, err := NewRunner(svr, WithLogger(tl), Period(50*time.Millisecond), ShutdownOnCancel())
+			if err != nil {
+				t.Fatalf("cannot create Runner: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			errs := make(chan error, 1)
+			go func() { errs <- r.Run(ctx) }()
+
+			<-r.Ready()
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+
+			got := <-errs
+
+			if !tc.want.isEqual(got) {
+				t.Errorf("Run(ctx) == %#v; wanted %#v", got, tc.want)
+			}
+
+			if lde, ok := got.(*LameDuckError); !ok || !lde.Canceled {
+				t.Errorf("Run(ctx) == %#v; wanted Canceled", got)
+			}
+
+			if o := OutcomeOf(got); o != tc.outcome {
+				t.Errorf("OutcomeOf(%#v) == %v; wanted %v", got, o, tc.outcome)
+			}
+
+			rp := r.LastReport()
+
+			if rp.Signal != cancelTrigger.String() {
+				t.Errorf("Report.Signal == %q; wanted %q", rp.Signal, cancelTrigger)
+			}
+
+			if rp.Shutdown == 0 {
+				t.Errorf("Shutdown was not called")
+			}
+		})
+	}
+}
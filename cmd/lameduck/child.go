@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"toolman.org/net/lameduck"
+)
+
+// child is a lameduck.Server wrapping a child process. Starting, signaling
+// and waiting for the process are handled by the embedded CommandServer; in
+// init mode, child adds zombie reaping and signal forwarding.
+type child struct {
+	*lameduck.CommandServer
+
+	triggers map[os.Signal]bool
+	init     bool
+	logger   *log.Logger
+	reaper   *reaper // valid while Serve is running in init mode
+
+	mu     sync.Mutex
+	reaped bool // whether status is valid
+	status syscall.WaitStatus
+}
+
+var _ lameduck.Server = (*child)(nil)
+
+// newChild returns a new child for the given command line. If init is true,
+// the child is run in its own process group (to which all signals are sent)
+// and all exited processes are reaped; see reaper.go.
+func newChild(args []string, triggers []os.Signal, rewrites map[os.Signal]syscall.Signal, init bool, logger *log.Logger) *child {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	tm := make(map[os.Signal]bool)
+	for _, s := range triggers {
+		tm[s] = true
+	}
+
+	rw := make(map[os.Signal]os.Signal)
+	for from, to := range rewrites {
+		rw[from] = to
+	}
+
+	c := &child{triggers: tm, init: init, logger: logger}
+
+	options := []lameduck.CommandOption{lameduck.ForwardSignal(rw)}
+	if init {
+		options = append(options, lameduck.ProcessGroup(), lameduck.WaitFunc(c.wait))
+	}
+
+	c.CommandServer = lameduck.Command(cmd, options...)
+
+	return c
+}
+
+// Serve starts the child process and waits for it to exit, forwarding
+// signals to it in the meantime (see startForwarder). If the child exits
+// before Shutdown is called, an error is returned.
+func (c *child) Serve(ctx context.Context) error {
+	if c.init {
+		c.reaper = startReaper(c.logger)
+		defer c.reaper.stop()
+	}
+
+	defer startForwarder(c).stop()
+
+	return c.CommandServer.Serve(ctx)
+}
+
+// wait is used in place of cmd.Wait in init mode. It reaps all exited
+// processes until cmd's process exits.
+func (c *child) wait(cmd *exec.Cmd) error {
+	ws := c.reaper.wait(cmd.Process.Pid)
+
+	c.mu.Lock()
+	c.reaped, c.status = true, ws
+	c.mu.Unlock()
+
+	return statusError(ws)
+}
+
+// exitStatus returns the exit status to be used by this command; either that
+// of the child or, if it was killed by a signal, 128 plus the signal number.
+func (c *child) exitStatus() int {
+	var ws syscall.WaitStatus
+
+	if c.init {
+		c.mu.Lock()
+		reaped := c.reaped
+		ws = c.status
+		c.mu.Unlock()
+
+		if !reaped {
+			return exitCannotStart
+		}
+	} else {
+		ps := c.ProcessState()
+		if ps == nil {
+			return exitCannotStart
+		}
+
+		var ok bool
+		if ws, ok = ps.Sys().(syscall.WaitStatus); !ok {
+			return ps.ExitCode()
+		}
+	}
+
+	if ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+
+	return ws.ExitStatus()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "lameduck-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return dir
+}
+
+// readPID reads a process ID written by a test script.
+func readPID(t *testing.T, path string) int {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read grandchild pid: %v", err)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		t.Fatalf("bad grandchild pid %q: %v", b, err)
+	}
+
+	return pid
+}
+
+// isZombie reports whether pid is a zombie process, along with whether it
+// exists at all.
+func isZombie(pid int) (zombie, exists bool) {
+	b, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false, false
+	}
+
+	// The state follows the (parenthesized) command name.
+	s := string(b)
+	if i := strings.LastIndexByte(s, ')'); i >= 0 && i+2 < len(s) {
+		return s[i+2] == 'Z', true
+	}
+
+	return false, true
+}
+
+func TestInitReapsOrphans(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("test requires /proc")
+	}
+
+	pidfile := filepath.Join(tempDir(t), "grandchild")
+
+	// The grandchild is orphaned immediately (its parent subshell exits) and
+	// exits itself shortly thereafter; it must then be reaped by lameduck.
+	script := fmt.Sprintf(`(sleep 0.05 & echo $! > %s); trap "exit 0" USR1; while :; do sleep 0.01; done`, pidfile)
+
+	if got := runWithSignal(t, syscall.SIGUSR1, 300*time.Millisecond, "--init", "--signals=USR1", "--", "sh", "-c", script); got != 0 {
+		t.Errorf("run(...) == %d; wanted 0", got)
+	}
+
+	gpid := readPID(t, pidfile)
+
+	if zombie, exists := isZombie(gpid); zombie || exists {
+		t.Errorf("grandchild %d not reaped (zombie:%v exists:%v)", gpid, zombie, exists)
+	}
+}
+
+func TestInitSignalsProcessGroup(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("test requires /proc")
+	}
+
+	pidfile := filepath.Join(tempDir(t), "grandchild")
+
+	// The child exits on SIGTERM but leaves its (long running) grandchild
+	// behind; only signaling the whole process group will stop it.
+	script := fmt.Sprintf(`sleep 30 & echo $! > %s; trap "exit 0" TERM; while :; do sleep 0.01; done`, pidfile)
+
+	got := runWithSignal(t, syscall.SIGUSR1, 200*time.Millisecond, "--init", "--signals=USR1", "--rewrite=USR1=TERM", "--period=1s", "--", "sh", "-c", script)
+	if got != 0 {
+		t.Errorf("run(...) == %d; wanted 0", got)
+	}
+
+	gpid := readPID(t, pidfile)
+
+	// Once signaled, the grandchild is reaped either by lameduck or (if it
+	// outlives lameduck) by whichever process inherits it.
+	deadline := time.Now().Add(2 * time.Second)
+
+	for time.Now().Before(deadline) {
+		if zombie, exists := isZombie(gpid); zombie || !exists {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	syscall.Kill(gpid, syscall.SIGKILL)
+	t.Errorf("grandchild %d not signaled", gpid)
+}
+
+func TestForwardSignals(t *testing.T) {
+	for _, mode := range []string{"--init=false", "--init"} {
+		// SIGHUP is not a trigger signal and so is forwarded as-is.
+		script := `trap "exit 5" HUP; while :; do sleep 0.01; done`
+
+		if got := runWithSignal(t, syscall.SIGHUP, 200*time.Millisecond, mode, "--", "sh", "-c", script); got != 5 {
+			t.Errorf("run(%s ...) == %d; wanted 5", mode, got)
+		}
+	}
+}
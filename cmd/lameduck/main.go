@@ -0,0 +1,130 @@
+// Command lameduck supervises an arbitrary child process, providing it with
+// coordinated lame-duck behavior even if it has no graceful shutdown of its
+// own.
+//
+// Usage:
+//
+//     lameduck [flags] -- command [args...]
+//
+// The child command is started with the same stdin, stdout and stderr as
+// lameduck itself. On receipt of one of the configured trigger signals, that
+// signal (or its replacement, see --rewrite) is forwarded to the child and
+// lameduck waits for the lame-duck period to elapse. If the child has not
+// exited by then, it is sent SIGKILL.
+//
+// SIGHUP, SIGQUIT, SIGUSR1, SIGUSR2, SIGWINCH, SIGALRM and SIGCONT (unless
+// configured as trigger signals) are forwarded to the child.
+//
+// Flags:
+//
+//     --period duration     The lame-duck period (default 3s)
+//     --signals list        Comma separated list of trigger signals (default INT,TERM)
+//     --rewrite FROM=TO     Forward signal FROM as signal TO (may be repeated)
+//     --init                Run in init mode (default true when running as PID 1)
+//
+// Signals may be given by name, with or without the "SIG" prefix, or by
+// number (e.g. TERM, SIGTERM or 15). For example, nginx performs a graceful
+// shutdown on SIGQUIT rather than SIGTERM:
+//
+//     lameduck --period=30s --rewrite=TERM=QUIT -- nginx -g 'daemon off;'
+//
+// Init mode
+//
+// When lameduck is a container's entrypoint, it runs as PID 1 and must take on
+// the duties of an init process. In init mode, lameduck:
+//
+//   - Starts its child in a new process group and sends all signals
+//     (including those forwarded) to that group, rather than just the child.
+//   - Reaps all exited processes, including orphaned descendants which would
+//     otherwise remain as zombies.
+//
+// If init mode is requested when not running as PID 1, lameduck registers
+// itself as a "child subreaper" (on Linux) so that orphaned descendants are
+// still reparented to, and reaped by, lameduck.
+//
+// Exit status
+//
+// The exit status of lameduck is that of its child or, if the child was
+// killed by a signal, 128 plus the signal number. If the child cannot be
+// started, lameduck exits with status 127.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+	"toolman.org/net/lameduck"
+)
+
+const exitCannotStart = 127
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	fs := pflag.NewFlagSet("lameduck", pflag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: lameduck [flags] -- command [args...]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+
+	period := fs.Duration("period", 3*time.Second, "the lame-duck period")
+	triggers := lameduck.SignalList{syscall.SIGINT, syscall.SIGTERM}
+	fs.Var(&triggers, "signals", "comma separated list of trigger signals")
+	rewrites := fs.StringSlice("rewrite", nil, "forward signal FROM as signal TO; given as FROM=TO (may be repeated)")
+	initMode := fs.Bool("init", os.Getpid() == 1, "run in init mode; reap zombies and signal the child's process group")
+
+	if err := fs.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	logger := log.New(os.Stderr, "lameduck: ", log.LstdFlags)
+
+	rw, err := parseRewrites(*rewrites)
+	if err != nil {
+		logger.Printf("invalid --rewrite: %v", err)
+		return 2
+	}
+
+	if *initMode && os.Getpid() != 1 {
+		if err := setSubreaper(); err != nil {
+			logger.Printf("cannot become child subreaper; orphans will not be reaped: %v", err)
+		}
+	}
+
+	// Once lame-duck mode begins, the Runner stops listening for its trigger
+	// signals. Keep them handled until the child has been reaped so that a
+	// repeated signal (e.g. a second Ctrl-C) cannot kill lameduck and leave
+	// its child unsupervised.
+	held := make(chan os.Signal, 1)
+	signal.Notify(held, triggers...)
+	defer signal.Stop(held)
+
+	c := newChild(fs.Args(), triggers, rw, *initMode, logger)
+
+	err = lameduck.Run(context.Background(), c,
+		lameduck.Period(*period),
+		lameduck.Signals(triggers...),
+		lameduck.WithStructuredLogger(lameduck.StdLogger(logger)))
+
+	if err != nil {
+		logger.Printf("%v", err)
+	}
+
+	return c.exitStatus()
+}
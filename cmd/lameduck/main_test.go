@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// envRunArgs, when set in its environment, causes the test binary to call
+// run with its command line arguments and exit rather than run any tests.
+// This keeps run's signal handling and, in init mode, its reaping (which
+// makes the calling process a child subreaper and reaps every exited child)
+// out of the test process itself.
+const envRunArgs = "LAMEDUCK_TEST_RUN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(envRunArgs) != "" {
+		os.Exit(run(os.Args[1:]))
+	}
+
+	os.Exit(m.Run())
+}
+
+// runWithSignal calls run(args) in a subprocess, sending it sig after the
+// given delay (and periodically thereafter) until it exits. If sig is zero,
+// no signal is sent. The subprocess's exit status is returned.
+func runWithSignal(t *testing.T, sig syscall.Signal, delay time.Duration, args ...string) int {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), envRunArgs+"=1")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("cannot start subprocess: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	if sig == 0 {
+		timer.Stop()
+	}
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case <-done:
+			return cmd.ProcessState.ExitCode()
+
+		case <-timer.C:
+			cmd.Process.Signal(sig)
+			timer.Reset(50 * time.Millisecond)
+
+		case <-timeout:
+			cmd.Process.Kill()
+			<-done
+			t.Fatalf("run(%q) did not return", args)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	cases := map[string]struct {
+		args   []string
+		signal syscall.Signal // sent repeatedly; zero for none
+		want   int
+	}{
+		"rewrite": {
+			args:   []string{"--signals=USR1", "--rewrite=USR1=TERM", "--", "sh", "-c", `trap "exit 7" TERM; while :; do sleep 0.01; done`},
+			signal: syscall.SIGUSR1,
+			want:   7,
+		},
+		"killed": {
+			args:   []string{"--signals=SIGUSR1", "--period=100ms", "--", "sh", "-c", `trap "" USR1; while :; do sleep 0.01; done`},
+			signal: syscall.SIGUSR1,
+			want:   128 + int(syscall.SIGKILL),
+		},
+		"repeated": {
+			args:   []string{"--signals=TERM", "--", "sh", "-c", `trap "sleep 0.3; exit 0" TERM; while :; do sleep 0.01; done`},
+			signal: syscall.SIGTERM,
+			want:   0,
+		},
+		"exited": {
+			args: []string{"--", "sh", "-c", "exit 3"},
+			want: 3,
+		},
+		"nostart": {
+			args: []string{"--", "/nonexistent/command"},
+			want: exitCannotStart,
+		},
+		"badsignal": {
+			args: []string{"--signals=NOPE", "--", "true"},
+			want: 2,
+		},
+	}
+
+	for label, tc := range cases {
+		tc := tc
+		t.Run(label, func(t *testing.T) {
+			if got := runWithSignal(t, tc.signal, 100*time.Millisecond, tc.args...); got != tc.want {
+				t.Errorf("run(%q) == %d; wanted %d", tc.args, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// reaper collects the exit status of every child process that exits, as is
+// required of a container's init process (PID 1). When running as PID 1 (or
+// as a "child subreaper"; see setSubreaper) this includes any orphaned
+// descendants which would otherwise remain as zombies.
+type reaper struct {
+	logger *log.Logger
+	sigs   chan os.Signal
+}
+
+func startReaper(logger *log.Logger) *reaper {
+	r := &reaper{logger: logger, sigs: make(chan os.Signal, 1)}
+	signal.Notify(r.sigs, syscall.SIGCHLD)
+	return r
+}
+
+func (r *reaper) stop() {
+	signal.Stop(r.sigs)
+}
+
+// wait reaps all exited processes until the one with the given pid has
+// exited, then returns its WaitStatus.
+func (r *reaper) wait(pid int) syscall.WaitStatus {
+	for {
+		if ws, ok := r.reap(pid); ok {
+			return ws
+		}
+		<-r.sigs
+	}
+}
+
+// reap collects all processes that have exited. If one of those was pid, its
+// WaitStatus is returned along with a true value.
+func (r *reaper) reap(pid int) (syscall.WaitStatus, bool) {
+	var (
+		found  bool
+		status syscall.WaitStatus
+	)
+
+	for {
+		var ws syscall.WaitStatus
+
+		wpid, err := syscall.Wait4(-1, &ws, syscall.WNOHANG, nil)
+		switch {
+		case err == syscall.EINTR:
+			continue
+
+		case err != nil || wpid <= 0:
+			return status, found
+
+		case wpid == pid:
+			found, status = true, ws
+
+		default:
+			r.logger.Printf("reaped orphaned process %d (%s)", wpid, describe(ws))
+		}
+	}
+}
+
+func describe(ws syscall.WaitStatus) string {
+	if ws.Signaled() {
+		return fmt.Sprintf("signal: %v", ws.Signal())
+	}
+	return fmt.Sprintf("exit status: %d", ws.ExitStatus())
+}
+
+// statusError returns an error describing ws if it does not indicate a
+// successful exit.
+func statusError(ws syscall.WaitStatus) error {
+	if ws.Exited() && ws.ExitStatus() == 0 {
+		return nil
+	}
+	return fmt.Errorf("child %s", describe(ws))
+}
+
+// forwardedSignals are those signals forwarded to the child (or, in init
+// mode, its process group) less any that trigger lame-duck mode. Without
+// this, they would either terminate lameduck itself or, when running as PID
+// 1 (where the kernel ignores signals for which there is no handler), be
+// lost.
+var forwardedSignals = []os.Signal{
+	syscall.SIGHUP,
+	syscall.SIGQUIT,
+	syscall.SIGUSR1,
+	syscall.SIGUSR2,
+	syscall.SIGWINCH,
+	syscall.SIGALRM,
+	syscall.SIGCONT,
+}
+
+type forwarder struct {
+	sigs chan os.Signal
+	done chan struct{}
+}
+
+// startForwarder forwards all forwardedSignals -- other than the child's
+// trigger signals -- to c's process (or process group).
+func startForwarder(c *child) *forwarder {
+	var sigs []os.Signal
+	for _, s := range forwardedSignals {
+		if !c.triggers[s] {
+			sigs = append(sigs, s)
+		}
+	}
+
+	f := &forwarder{sigs: make(chan os.Signal, len(sigs)), done: make(chan struct{})}
+	signal.Notify(f.sigs, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-f.done:
+				return
+			case s := <-f.sigs:
+				c.Signal(s)
+			}
+		}
+	}()
+
+	return f
+}
+
+func (f *forwarder) stop() {
+	signal.Stop(f.sigs)
+	close(f.done)
+}
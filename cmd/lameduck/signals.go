@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"toolman.org/net/lameduck"
+)
+
+// parseRewrites converts a list of "FROM=TO" signal pairs into a map.
+func parseRewrites(pairs []string) (map[os.Signal]syscall.Signal, error) {
+	rw := make(map[os.Signal]syscall.Signal)
+
+	for _, p := range pairs {
+		parts := strings.SplitN(p, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not of the form FROM=TO", p)
+		}
+
+		from, err := lameduck.ParseSignal(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		to, err := lameduck.ParseSignal(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		rw[from] = to
+	}
+
+	return rw, nil
+}
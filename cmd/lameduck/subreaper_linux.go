@@ -0,0 +1,11 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// setSubreaper marks the current process as a "child subreaper" so that
+// orphaned descendants are reparented to it (rather than to PID 1) and may
+// therefore be reaped by it. This allows init mode to work, and be tested,
+// when not running as PID 1.
+func setSubreaper() error {
+	return unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 1, 0, 0, 0)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "errors"
+
+func setSubreaper() error {
+	return errors.New("child subreaper not supported on this platform")
+}
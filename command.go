@@ -0,0 +1,357 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+// ErrProcessExited is returned by a CommandServer's Serve method when its
+// child process exits successfully, but unexpectedly (i.e. before Shutdown
+// is called).
+var ErrProcessExited = errors.New("process exited unexpectedly")
+
+// CommandServer is a Server that runs an *exec.Cmd as a child process,
+// allowing child processes to be drained along with their parent.
+//
+//   - Serve starts the command and waits for it to exit. If it exits before
+//     Shutdown is called, Serve returns its error (or ErrProcessExited). If
+//     Serve's Context is done first, the process (or process group) is sent
+//     SIGKILL and, once it has exited, the Context's error is returned.
+//   - Shutdown sends a signal (SIGTERM, by default) to the process, or its
+//     process group, and waits for it to exit until the Context is done.
+//   - Close sends SIGKILL to the process, or its process group, and waits for
+//     it to exit.
+//
+// Serve may be called more than once (e.g. when using the RestartOnFailure
+// Option or reusing a Runner) but not concurrently. The first call starts the
+// given command; each subsequent call starts a fresh copy having the same
+// Path, Args, Env, Dir, standard I/O, ExtraFiles and SysProcAttr.
+//
+// Use Command to create a CommandServer.
+type CommandServer struct {
+	tmpl     *exec.Cmd
+	signal   os.Signal
+	rewrites map[os.Signal]os.Signal
+	forward  bool
+	group    bool
+	waitFunc func(*exec.Cmd) error
+
+	mu       sync.Mutex
+	used     bool     // whether tmpl has been started
+	proc     *process // the most recently started process
+	stopping bool
+}
+
+// process holds the state for a single call to a CommandServer's Serve method.
+type process struct {
+	cmd     *exec.Cmd
+	started chan struct{} // closed once Start has been attempted
+	exited  chan struct{} // closed once the process has exited
+	err     error         // from Start or Wait; valid once exited is closed
+}
+
+var _ Server = (*CommandServer)(nil)
+
+// CommandOption is the interface implemented by types that alter the
+// behavior of a CommandServer.
+type CommandOption interface {
+	setCmd(*CommandServer)
+}
+
+// Command returns a CommandServer for the given (unstarted) cmd.
+func Command(cmd *exec.Cmd, options ...CommandOption) *CommandServer {
+	cs := &CommandServer{
+		tmpl:   cmd,
+		signal: syscall.SIGTERM,
+	}
+
+	for _, o := range options {
+		o.setCmd(cs)
+	}
+
+	if cs.group {
+		if cmd.SysProcAttr == nil {
+			cmd.SysProcAttr = new(syscall.SysProcAttr)
+		}
+		cmd.SysProcAttr.Setpgid = true
+	}
+
+	return cs
+}
+
+// nextCmd returns the command to be started by the next call to Serve.
+func (cs *CommandServer) nextCmd() *exec.Cmd {
+	if !cs.used {
+		cs.used = true
+		return cs.tmpl
+	}
+
+	t := cs.tmpl
+
+	return &exec.Cmd{
+		Path:        t.Path,
+		Args:        t.Args,
+		Env:         t.Env,
+		Dir:         t.Dir,
+		Stdin:       t.Stdin,
+		Stdout:      t.Stdout,
+		Stderr:      t.Stderr,
+		ExtraFiles:  t.ExtraFiles,
+		SysProcAttr: t.SysProcAttr,
+	}
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// ShutdownSignal returns a CommandOption that alters the signal sent to the
+// child process by Shutdown. The default is SIGTERM.
+func ShutdownSignal(sig os.Signal) CommandOption {
+	return &shutdownSignal{sig}
+}
+
+type shutdownSignal struct {
+	sig os.Signal
+}
+
+func (o *shutdownSignal) setCmd(cs *CommandServer) {
+	cs.signal = o.sig
+}
+
+// ForwardSignal returns a CommandOption that causes Shutdown to send the
+// signal that triggered lame-duck mode (see TriggerSignal) to the child
+// process rather than the ShutdownSignal. If that signal is a key in the
+// given rewrites map, its value is sent instead. If lame-duck mode was not
+// triggered by a signal, the ShutdownSignal is used.
+func ForwardSignal(rewrites map[os.Signal]os.Signal) CommandOption {
+	return &forwardSignal{rewrites}
+}
+
+type forwardSignal struct {
+	rewrites map[os.Signal]os.Signal
+}
+
+func (o *forwardSignal) setCmd(cs *CommandServer) {
+	cs.forward = true
+	cs.rewrites = o.rewrites
+}
+
+// ProcessGroup returns a CommandOption that starts the child process in a new
+// process group. All signals sent by Shutdown and Close are then sent to the
+// entire process group.
+func ProcessGroup() CommandOption {
+	return new(processGroup)
+}
+
+type processGroup struct{}
+
+func (*processGroup) setCmd(cs *CommandServer) {
+	cs.group = true
+}
+
+// WaitFunc returns a CommandOption that replaces the call to the child
+// process's Wait method (made by Serve once the process has started) with a
+// call to f, which must not return until the process has exited. This is
+// intended for programs, such as an init process, that reap child processes
+// themselves.
+func WaitFunc(f func(*exec.Cmd) error) CommandOption {
+	return waitFunc(f)
+}
+
+type waitFunc func(*exec.Cmd) error
+
+func (f waitFunc) setCmd(cs *CommandServer) {
+	cs.waitFunc = f
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// Serve implements Server.
+func (cs *CommandServer) Serve(ctx context.Context) error {
+	p := &process{started: make(chan struct{}), exited: make(chan struct{})}
+
+	cs.mu.Lock()
+	p.cmd = cs.nextCmd()
+	cs.proc = p
+	cs.stopping = false
+	cs.mu.Unlock()
+
+	if err := p.cmd.Start(); err != nil {
+		p.err = err
+		close(p.started)
+		close(p.exited)
+		return err
+	}
+
+	close(p.started)
+
+	go func() {
+		if cs.waitFunc != nil {
+			p.err = cs.waitFunc(p.cmd)
+		} else {
+			p.err = p.cmd.Wait()
+		}
+		close(p.exited)
+	}()
+
+	var ctxErr error
+
+	select {
+	case <-p.exited:
+	case <-ctx.Done():
+		// Don't leave the process running once our caller has given up.
+		ctxErr = ctx.Err()
+		cs.signalProcess(p, syscall.SIGKILL)
+		<-p.exited
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	switch {
+	case cs.stopping:
+		return nil
+	case ctxErr != nil:
+		return ctxErr
+	case p.err != nil:
+		return p.err
+	default:
+		return ErrProcessExited
+	}
+}
+
+// Shutdown implements Server.
+func (cs *CommandServer) Shutdown(ctx context.Context) error {
+	cs.mu.Lock()
+	cs.stopping = true
+	cs.mu.Unlock()
+
+	sig := cs.signal
+
+	if trig, ok := TriggerSignal(ctx).(syscall.Signal); cs.forward && ok {
+		sig = trig
+		if rw, ok := cs.rewrites[trig]; ok {
+			sig = rw
+		}
+	}
+
+	if err := cs.kill(ctx, sig); err != nil {
+		return err
+	}
+
+	return cs.wait(ctx)
+}
+
+// Close implements Server.
+func (cs *CommandServer) Close() error {
+	ctx := context.Background()
+
+	if err := cs.kill(ctx, syscall.SIGKILL); err != nil {
+		return err
+	}
+
+	return cs.wait(ctx)
+}
+
+// Signal sends sig to the running child process or, if the ProcessGroup
+// CommandOption is in effect, its process group. If no process is running,
+// Signal does nothing.
+func (cs *CommandServer) Signal(sig os.Signal) error {
+	p := cs.current()
+
+	if p == nil {
+		return nil
+	}
+
+	select {
+	case <-p.started:
+	default:
+		return nil
+	}
+
+	return cs.signalProcess(p, sig)
+}
+
+// ProcessState returns the ProcessState of the most recently started child
+// process, or nil if it has not (yet) exited.
+func (cs *CommandServer) ProcessState() *os.ProcessState {
+	p := cs.current()
+
+	if p == nil {
+		return nil
+	}
+
+	select {
+	case <-p.exited:
+		return p.cmd.ProcessState
+	default:
+		return nil
+	}
+}
+
+func (cs *CommandServer) current() *process {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	return cs.proc
+}
+
+func (cs *CommandServer) kill(ctx context.Context, sig os.Signal) error {
+	p := cs.current()
+
+	if p == nil {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.started:
+	}
+
+	return cs.signalProcess(p, sig)
+}
+
+// signalProcess sends sig to p (or its process group) unless it has already
+// exited. p must have been started.
+func (cs *CommandServer) signalProcess(p *process, sig os.Signal) error {
+	select {
+	case <-p.exited:
+		return nil
+	default:
+	}
+
+	ssig, ok := sig.(syscall.Signal)
+	if !ok {
+		return p.cmd.Process.Signal(sig)
+	}
+
+	pid := p.cmd.Process.Pid
+	if cs.group {
+		pid = -pid
+	}
+
+	if err := syscall.Kill(pid, ssig); err != nil && err != syscall.ESRCH {
+		return err
+	}
+
+	return nil
+}
+
+func (cs *CommandServer) wait(ctx context.Context) error {
+	p := cs.current()
+
+	if p == nil {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.exited:
+		return nil
+	}
+}
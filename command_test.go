@@ -0,0 +1,110 @@
+package lameduck
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestCommand(t *testing.T) {
+	cases := map[string]struct {
+		script  string
+		options []CommandOption
+		signal  bool
+		want    Outcome
+	}{
+		"graceful": {
+			script: `trap "exit 0" TERM; while :; do sleep 0.01; done`,
+			signal: true,
+			want:   Clean,
+		},
+		"forward": {
+			script:  `trap "exit 0" QUIT; while :; do sleep 0.01; done`,
+			options: []CommandOption{ForwardSignal(map[os.Signal]os.Signal{unix.SIGTERM: unix.SIGQUIT})},
+			signal:  true,
+			want:    Clean,
+		},
+		"killed": {
+			script:  `trap "" TERM; while :; do sleep 0.01; done`,
+			options: []CommandOption{ProcessGroup()},
+			signal:  true,
+			want:    Expired,
+		},
+		"exited": {
+			script: "exit 0",
+			want:   ServeFailed,
+		},
+		"failed": {
+			script: "exit 3",
+			want:   ServeFailed,
+		},
+	}
+
+	for label, tc := range cases {
+		tc := tc
+		t.Run(label, func(t *testing.T) {
+			ts := injectSignaller()
+			defer ts.revert()
+
+			tl := &testLogger{t.Logf}
+			cs := Command(exec.Command("sh", "-c", tc.script), tc.options...)
+
+			r, err := NewRunner(cs, WithLogger(tl), Period(100*time.Millisecond))
+			if err != nil {
+				t.Fatalf("cannot create Runner: %v", err)
+			}
+
+			errs := make(chan error, 1)
+			go func() { errs <- r.Run(context.Background()) }()
+
+			if tc.signal {
+				<-r.Ready()
+				time.Sleep(50 * time.Millisecond)
+				ts.emit(unix.SIGTERM)
+			}
+
+			err = <-errs
+
+			if got := OutcomeOf(err); got != tc.want {
+				t.Errorf("Run(ctx) == %#v (%v); wanted %v", err, got, tc.want)
+			}
+
+			if cs.ProcessState() == nil {
+				t.Error("cs.ProcessState() == nil after Run")
+			}
+		})
+	}
+}
+
+func TestCommandServeAgain(t *testing.T) {
+	cs := Command(exec.Command("sh", "-c", "exit 0"))
+
+	for i := 1; i <= 2; i++ {
+		if err := cs.Serve(context.Background()); err != ErrProcessExited {
+			t.Errorf("Serve #%d == %v; wanted %v", i, err, ErrProcessExited)
+		}
+
+		if cs.ProcessState() == nil {
+			t.Errorf("cs.ProcessState() == nil after Serve #%d", i)
+		}
+	}
+}
+
+func TestCommandServeCanceled(t *testing.T) {
+	cs := Command(exec.Command("sleep", "30"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := cs.Serve(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Serve(ctx) == %v; wanted %v", err, context.DeadlineExceeded)
+	}
+
+	if ps := cs.ProcessState(); ps == nil || ps.Success() {
+		t.Errorf("cs.ProcessState() == %v; wanted killed process", ps)
+	}
+}
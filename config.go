@@ -0,0 +1,249 @@
+package lameduck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Policy describes lame-duck configuration as may be read from a JSON policy
+// file (see LoadPolicy) or environment variables (see FromEnv). Durations are
+// given as strings parseable by time.ParseDuration (e.g. "2.5s") and signals
+// by name or number (e.g. "TERM" or "15"). Exit codes are keyed by "clean",
+// "expired", "serve_failed", "shutdown_error" and "forced"; those not given
+// retain their DefaultExitCodes values.
+//
+//     {
+//       "period":        "30s",
+//       "drain_delay":   "5s",
+//       "signals":       ["TERM", "INT"],
+//       "close_timeout": "10s",
+//       "exit_codes":    {"expired": 75}
+//     }
+//
+// Empty (or omitted) fields leave the corresponding setting unchanged.
+type Policy struct {
+	Period       string         `json:"period,omitempty"`
+	DrainDelay   string         `json:"drain_delay,omitempty"`
+	Signals      []string       `json:"signals,omitempty"`
+	CloseTimeout string         `json:"close_timeout,omitempty"`
+	StopTimeout  string         `json:"stop_timeout,omitempty"`
+	ExitCodes    map[string]int `json:"exit_codes,omitempty"`
+}
+
+// LoadPolicy reads the JSON policy file at path and returns the Options it
+// describes. Unknown fields and invalid values are rejected.
+func LoadPolicy(path string) ([]Option, error) {
+	p, err := readPolicy(path)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := p.options(jsonField)
+	if err != nil {
+		return nil, fmt.Errorf("policy file %q: %w", path, err)
+	}
+
+	return opts, nil
+}
+
+// Options returns the Options described by the receiver, or an error if any
+// of its values are invalid.
+func (p *Policy) Options() ([]Option, error) {
+	return p.options(jsonField)
+}
+
+// Environment variables consulted by FromEnv.
+const (
+	EnvPolicyFile   = "LAMEDUCK_POLICY_FILE"
+	EnvPeriod       = "LAMEDUCK_PERIOD"
+	EnvDrainDelay   = "LAMEDUCK_DRAIN_DELAY"
+	EnvSignals      = "LAMEDUCK_SIGNALS"
+	EnvCloseTimeout = "LAMEDUCK_CLOSE_TIMEOUT"
+	EnvStopTimeout  = "LAMEDUCK_STOP_TIMEOUT"
+)
+
+// FromEnv returns the Options described by the LAMEDUCK_* environment
+// variables:
+//
+//     LAMEDUCK_POLICY_FILE    Path to a JSON policy file (see LoadPolicy)
+//     LAMEDUCK_PERIOD         The lame-duck period (see Period)
+//     LAMEDUCK_DRAIN_DELAY    Delay before Shutdown (see DrainDelay)
+//     LAMEDUCK_SIGNALS        Comma separated trigger signals (see Signals)
+//     LAMEDUCK_CLOSE_TIMEOUT  Time allowed for Close (see CloseTimeout)
+//     LAMEDUCK_STOP_TIMEOUT   Time allowed per OnStop closer (see StopTimeout)
+//
+// If a policy file is given, it is read first; other variables then override
+// its values. Unset or empty variables are ignored. An error is returned if
+// any value is invalid.
+func FromEnv() ([]Option, error) {
+	p := new(Policy)
+
+	if path := os.Getenv(EnvPolicyFile); path != "" {
+		var err error
+		if p, err = readPolicy(path); err != nil {
+			return nil, err
+		}
+
+		// Validate the file on its own so errors refer to its fields
+		if _, err := p.options(jsonField); err != nil {
+			return nil, fmt.Errorf("policy file %q: %w", path, err)
+		}
+	}
+
+	if v := os.Getenv(EnvPeriod); v != "" {
+		p.Period = v
+	}
+
+	if v := os.Getenv(EnvDrainDelay); v != "" {
+		p.DrainDelay = v
+	}
+
+	if v := os.Getenv(EnvSignals); v != "" {
+		p.Signals = strings.Split(v, ",")
+	}
+
+	if v := os.Getenv(EnvCloseTimeout); v != "" {
+		p.CloseTimeout = v
+	}
+
+	if v := os.Getenv(EnvStopTimeout); v != "" {
+		p.StopTimeout = v
+	}
+
+	return p.options(envVar)
+}
+
+func readPolicy(path string) (*Policy, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policy file: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+
+	p := new(Policy)
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("policy file %q: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// Field identifiers passed to a Policy's options method for error reporting.
+const (
+	fieldPeriod = iota
+	fieldDrainDelay
+	fieldSignals
+	fieldCloseTimeout
+	fieldStopTimeout
+	fieldExitCodes
+)
+
+func jsonField(f int) string {
+	return [...]string{"period", "drain_delay", "signals", "close_timeout", "stop_timeout", "exit_codes"}[f]
+}
+
+func envVar(f int) string {
+	return [...]string{EnvPeriod, EnvDrainDelay, EnvSignals, EnvCloseTimeout, EnvStopTimeout, "exit_codes"}[f]
+}
+
+// options converts the receiver into Options using name to identify any
+// invalid fields.
+func (p *Policy) options(name func(int) string) ([]Option, error) {
+	var opts []Option
+
+	durations := []struct {
+		field int
+		value string
+		min   time.Duration
+		opt   func(time.Duration) Option
+	}{
+		{fieldPeriod, p.Period, 1, Period},
+		{fieldDrainDelay, p.DrainDelay, 0, DrainDelay},
+		{fieldCloseTimeout, p.CloseTimeout, 0, CloseTimeout},
+		{fieldStopTimeout, p.StopTimeout, 0, StopTimeout},
+	}
+
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+
+		v, err := time.ParseDuration(d.value)
+		switch {
+		case err != nil:
+			return nil, fmt.Errorf("%s: invalid duration %q", name(d.field), d.value)
+		case d.min > 0 && v < d.min:
+			return nil, fmt.Errorf("%s: must be greater than zero", name(d.field))
+		case v < d.min:
+			return nil, fmt.Errorf("%s: must not be negative", name(d.field))
+		}
+
+		opts = append(opts, d.opt(v))
+	}
+
+	if p.Signals != nil {
+		if len(p.Signals) == 0 {
+			return nil, fmt.Errorf("%s: no lame-duck signals defined", name(fieldSignals))
+		}
+
+		var sigs SignalList
+		if err := sigs.Set(strings.Join(p.Signals, ",")); err != nil {
+			return nil, fmt.Errorf("%s: %v", name(fieldSignals), err)
+		}
+		opts = append(opts, Signals(sigs...))
+	}
+
+	if len(p.ExitCodes) != 0 {
+		ec, err := exitCodesFrom(p.ExitCodes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name(fieldExitCodes), err)
+		}
+		opts = append(opts, WithExitCodes(ec))
+	}
+
+	return opts, nil
+}
+
+// exitCodesFrom applies the exit codes in m (keyed by Outcome name) to
+// DefaultExitCodes.
+func exitCodesFrom(m map[string]int) (ExitCodes, error) {
+	ec := DefaultExitCodes
+
+	fields := map[string]*int{
+		Clean.String():         &ec.Clean,
+		Expired.String():       &ec.Expired,
+		ServeFailed.String():   &ec.ServeFailed,
+		ShutdownError.String(): &ec.ShutdownError,
+		Forced.String():        &ec.Forced,
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p, ok := fields[k]
+		if !ok {
+			return ec, fmt.Errorf("unknown outcome %q", k)
+		}
+
+		if v := m[k]; v < 0 || v > 255 {
+			return ec, fmt.Errorf("%s: exit code %d out of range [0, 255]", k, v)
+		} else {
+			*p = v
+		}
+	}
+
+	return ec, nil
+}
+
@@ -0,0 +1,147 @@
+package lameduck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "lameduck-policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "policy.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func setEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	for k, v := range env {
+		old, ok := os.LookupEnv(k)
+		os.Setenv(k, v)
+
+		k := k
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(k, old)
+			} else {
+				os.Unsetenv(k)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := writePolicy(t, `{
+		"period":        "10s",
+		"drain_delay":   "2s",
+		"signals":       ["TERM", "SIGUSR1"],
+		"close_timeout": "5s",
+		"exit_codes":    {"expired": 75}
+	}`)
+
+	opts, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadPolicy(%q): %v", path, err)
+	}
+
+	r, err := NewRunner(newTestServer(nil, nil, nil, nil), opts...)
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	wantCodes := DefaultExitCodes
+	wantCodes.Expired = 75
+
+	if r.period != 10*time.Second || r.drainDelay != 2*time.Second || r.closeTimeout != 5*time.Second {
+		t.Errorf("durations: period=%v drainDelay=%v closeTimeout=%v", r.period, r.drainDelay, r.closeTimeout)
+	}
+
+	if want := []os.Signal{unix.SIGTERM, unix.SIGUSR1}; !reflect.DeepEqual(r.signals, want) {
+		t.Errorf("signals: got %v; wanted %v", r.signals, want)
+	}
+
+	if r.exitCodes != wantCodes {
+		t.Errorf("exitCodes: got %+v; wanted %+v", r.exitCodes, wantCodes)
+	}
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	cases := []struct {
+		content string
+		want    string
+	}{
+		{`{"period": "0s"}`, "period: must be greater than zero"},
+		{`{"period": "soon"}`, `period: invalid duration "soon"`},
+		{`{"drain_delay": "-1s"}`, "drain_delay: must not be negative"},
+		{`{"signals": []}`, "signals: no lame-duck signals defined"},
+		{`{"signals": ["NOPE"]}`, `signals: unknown signal "NOPE"`},
+		{`{"exit_codes": {"bogus": 1}}`, `exit_codes: unknown outcome "bogus"`},
+		{`{"exit_codes": {"clean": 256}}`, "exit_codes: clean: exit code 256 out of range"},
+		{`{"perod": "1s"}`, `unknown field "perod"`},
+	}
+
+	for _, tc := range cases {
+		_, err := LoadPolicy(writePolicy(t, tc.content))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("LoadPolicy(%s) == %v; wanted error containing %q", tc.content, err, tc.want)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	path := writePolicy(t, `{"period": "10s", "drain_delay": "2s"}`)
+
+	setEnv(t, map[string]string{
+		EnvPolicyFile: path,
+		EnvPeriod:     "20s",
+		EnvSignals:    "HUP,15",
+	})
+
+	opts, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv(): %v", err)
+	}
+
+	r, err := NewRunner(newTestServer(nil, nil, nil, nil), opts...)
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	if r.period != 20*time.Second {
+		t.Errorf("period: got %v; wanted %v", r.period, 20*time.Second)
+	}
+
+	if r.drainDelay != 2*time.Second {
+		t.Errorf("drainDelay: got %v; wanted %v", r.drainDelay, 2*time.Second)
+	}
+
+	if want := []os.Signal{unix.SIGHUP, unix.SIGTERM}; !reflect.DeepEqual(r.signals, want) {
+		t.Errorf("signals: got %v; wanted %v", r.signals, want)
+	}
+}
+
+func TestFromEnvError(t *testing.T) {
+	setEnv(t, map[string]string{EnvCloseTimeout: "-5s"})
+
+	want := EnvCloseTimeout + ": must not be negative"
+	if _, err := FromEnv(); err == nil || err.Error() != want {
+		t.Errorf("FromEnv() == %v; wanted %q", err, want)
+	}
+}
@@ -0,0 +1,289 @@
+package lameduck
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ConnRegistry tracks hijacked or otherwise long-lived connections that are
+// not covered by a Server's Shutdown method. For example, http.Server's
+// Shutdown method explicitly ignores hijacked connections (such as
+// websockets) and, without some other coordination, these would always
+// outlive lame-duck mode only to be cut off abruptly.
+//
+// Each Runner has its own ConnRegistry (see Runner.Conns and ConnsFromContext)
+// which is coordinated with lame-duck mode as follows:
+//
+//   - When lame-duck mode begins, each registered connection's notify
+//     function is called.
+//   - After the Server's Shutdown method returns successfully, the Runner
+//     waits (for the remainder of the lame-duck period) for all registered
+//     connections to be released.
+//   - If the lame-duck period expires, all connections still registered are
+//     forcibly closed along with the Server.
+//
+// A ConnRegistry may also track an http.Server's keep-alive connections by
+// way of its ConnState method. These are not waited upon (that's the job of
+// the Server's Shutdown method) but are candidates for gradual shedding; see
+// the ShedConnections Option for details.
+//
+// A nil *ConnRegistry is valid and tracks nothing.
+type ConnRegistry struct {
+	mu        sync.Mutex
+	conns     map[*trackedConn]struct{}
+	keepalive map[net.Conn]http.ConnState
+	lduck     bool
+	drained   chan struct{}
+}
+
+type trackedConn struct {
+	closer io.Closer
+	notify func()
+}
+
+func newConnRegistry() *ConnRegistry {
+	return &ConnRegistry{
+		conns:     make(map[*trackedConn]struct{}),
+		keepalive: make(map[net.Conn]http.ConnState),
+	}
+}
+
+// Register adds c to the receiver's set of tracked connections. The given
+// notify function (which may be nil) will be called, in its own goroutine,
+// when lame-duck mode begins -- or immediately, if lame-duck mode is already
+// underway -- and should be used to inform the remote peer that the
+// connection is going away.
+//
+// The returned release function must be called once c is no longer in use;
+// it is safe to call more than once.
+func (cr *ConnRegistry) Register(c io.Closer, notify func()) (release func()) {
+	if cr == nil || c == nil {
+		return func() {}
+	}
+
+	tc := &trackedConn{closer: c, notify: notify}
+
+	cr.mu.Lock()
+	cr.conns[tc] = struct{}{}
+	lduck := cr.lduck
+	cr.mu.Unlock()
+
+	if lduck {
+		tc.notifyAsync()
+	}
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { cr.release(tc) })
+	}
+}
+
+// ConnState tracks the state of an http.Server's keep-alive connections and
+// is suitable for assignment to its ConnState field:
+//
+//     svr.ConnState = runner.Conns().ConnState
+//
+func (cr *ConnRegistry) ConnState(c net.Conn, s http.ConnState) {
+	if cr == nil {
+		return
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	switch s {
+	case http.StateHijacked, http.StateClosed:
+		delete(cr.keepalive, c)
+	default:
+		cr.keepalive[c] = s
+	}
+}
+
+// Len returns the number of connections currently registered with the
+// receiver.
+func (cr *ConnRegistry) Len() int {
+	if cr == nil {
+		return 0
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	return len(cr.conns)
+}
+
+// keepAliveLen returns the number of keep-alive connections tracked by the
+// receiver's ConnState method.
+func (cr *ConnRegistry) keepAliveLen() int {
+	if cr == nil {
+		return 0
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	return len(cr.keepalive)
+}
+
+func (cr *ConnRegistry) release(tc *trackedConn) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	delete(cr.conns, tc)
+
+	if len(cr.conns) == 0 && cr.drained != nil {
+		close(cr.drained)
+		cr.drained = nil
+	}
+}
+
+func (tc *trackedConn) notifyAsync() {
+	if tc.notify != nil {
+		go tc.notify()
+	}
+}
+
+// reset clears the receiver's lame-duck mode, along with all tracked
+// connections, in preparation for a new run. Release functions returned
+// before the reset remain safe to call.
+func (cr *ConnRegistry) reset() {
+	if cr == nil {
+		return
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.conns = make(map[*trackedConn]struct{})
+	cr.keepalive = make(map[net.Conn]http.ConnState)
+	cr.drained = nil
+	cr.lduck = false
+}
+
+// beginLameDuck marks the receiver as being in lame-duck mode and returns the
+// connections registered at that time. Connections registered afterward are
+// notified immediately.
+func (cr *ConnRegistry) beginLameDuck() []*trackedConn {
+	if cr == nil {
+		return nil
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.lduck = true
+
+	conns := make([]*trackedConn, 0, len(cr.conns))
+	for tc := range cr.conns {
+		conns = append(conns, tc)
+	}
+
+	return conns
+}
+
+// notifyAll marks the receiver as being in lame-duck mode and calls the
+// notify function for each registered connection.
+func (cr *ConnRegistry) notifyAll() {
+	for _, tc := range cr.beginLameDuck() {
+		tc.notifyAsync()
+	}
+}
+
+// keepAliveConns returns all keep-alive connections currently tracked.
+func (cr *ConnRegistry) keepAliveConns() []net.Conn {
+	if cr == nil {
+		return nil
+	}
+
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	conns := make([]net.Conn, 0, len(cr.keepalive))
+	for c := range cr.keepalive {
+		conns = append(conns, c)
+	}
+
+	return conns
+}
+
+// isKeepAlive reports whether c is still a tracked keep-alive connection.
+func (cr *ConnRegistry) isKeepAlive(c net.Conn) bool {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	_, ok := cr.keepalive[c]
+	return ok
+}
+
+// closeIfIdle closes c if it is (still) an idle keep-alive connection and
+// reports whether it did so.
+func (cr *ConnRegistry) closeIfIdle(c net.Conn) bool {
+	cr.mu.Lock()
+	if cr.keepalive[c] != http.StateIdle {
+		cr.mu.Unlock()
+		return false
+	}
+	delete(cr.keepalive, c)
+	cr.mu.Unlock()
+
+	c.Close()
+	return true
+}
+
+// wait blocks until all registered connections have been released or ctx
+// is done, in which case ctx.Err() is returned.
+func (cr *ConnRegistry) wait(ctx context.Context) error {
+	if cr == nil {
+		return nil
+	}
+
+	cr.mu.Lock()
+	if len(cr.conns) == 0 {
+		cr.mu.Unlock()
+		return nil
+	}
+
+	if cr.drained == nil {
+		cr.drained = make(chan struct{})
+	}
+	drained := cr.drained
+	cr.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-drained:
+		return nil
+	}
+}
+
+// closeAll closes and releases all registered connections and returns the
+// first error encountered, if any.
+func (cr *ConnRegistry) closeAll() error {
+	if cr == nil {
+		return nil
+	}
+
+	cr.mu.Lock()
+	conns := make([]*trackedConn, 0, len(cr.conns))
+	for tc := range cr.conns {
+		conns = append(conns, tc)
+	}
+	cr.mu.Unlock()
+
+	var err error
+
+	for _, tc := range conns {
+		if cerr := tc.closer.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		cr.release(tc)
+	}
+
+	return err
+}
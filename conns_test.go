@@ -0,0 +1,112 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+type testConn struct {
+	closed chan struct{}
+}
+
+func newTestConn() *testConn {
+	return &testConn{closed: make(chan struct{})}
+}
+
+func (tc *testConn) Close() error {
+	close(tc.closed)
+	return nil
+}
+
+func TestConnRegistry(t *testing.T) {
+	cr := newConnRegistry()
+
+	notified := make(chan struct{}, 2)
+	notify := func() { notified <- struct{}{} }
+
+	release := cr.Register(newTestConn(), notify)
+
+	if got := cr.Len(); got != 1 {
+		t.Errorf("cr.Len() == %d; wanted 1", got)
+	}
+
+	cr.notifyAll()
+	<-notified
+
+	// Registered after lame-duck has begun; should be notified immediately.
+	late := newTestConn()
+	cr.Register(late, notify)
+	<-notified
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := cr.wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("cr.wait(ctx) == %v; wanted %v", err, context.DeadlineExceeded)
+	}
+
+	release()
+	release()
+
+	if err := cr.closeAll(); err != nil {
+		t.Errorf("cr.closeAll() == %v; wanted nil", err)
+	}
+
+	select {
+	case <-late.closed:
+	default:
+		t.Error("tracked connection not closed by closeAll")
+	}
+
+	if err := cr.wait(context.Background()); err != nil {
+		t.Errorf("cr.wait(ctx) == %v; wanted nil", err)
+	}
+
+	var nilcr *ConnRegistry
+	nilcr.Register(newTestConn(), nil)()
+
+	if got := nilcr.Len(); got != 0 {
+		t.Errorf("nilcr.Len() == %d; wanted 0", got)
+	}
+}
+
+func TestRunClosesTrackedConns(t *testing.T) {
+	ts := injectSignaller()
+	defer ts.revert()
+
+	tl := &testLogger{t.Logf}
+	svr := &ctxServer{newTestServer(tl, nil, nil, nil), make(chan context.Context, 1)}
+	svr.shutdown.finish()
+
+	r, err := NewRunner(svr, WithLogger(tl), Period(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- r.Run(context.Background()) }()
+
+	conn := newTestConn()
+	notified := make(chan struct{})
+	ConnsFromContext(<-svr.ctxs).Register(conn, func() { close(notified) })
+
+	time.Sleep(10 * time.Millisecond)
+	ts.emit(unix.SIGTERM)
+
+	<-notified
+
+	var lde *LameDuckError
+	if err := <-errs; !errors.As(err, &lde) || !lde.Expired {
+		t.Errorf("Run(ctx) == %#v; wanted &LameDuckError{Expired: true}", err)
+	}
+
+	select {
+	case <-conn.closed:
+	default:
+		t.Error("tracked connection not closed after lame-duck expiry")
+	}
+}
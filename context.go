@@ -0,0 +1,68 @@
+package lameduck
+
+import (
+	"context"
+	"os"
+)
+
+type (
+	runnerKey struct{}
+	readyKey  struct{}
+	signalKey struct{}
+)
+
+// FromContext returns the lame-duck channel for the Runner associated with
+// ctx along with a boolean value indicating whether such a Runner was found.
+// The returned channel is closed when lame-duck mode begins.
+//
+// The Context passed to a Server's Serve method is always associated with
+// the Runner that called it. This allows code running inside Serve (e.g.
+// streaming handlers) to notify clients before the lame-duck period ends.
+// For an http.Server, this Context may be propagated to request handlers
+// using its BaseContext field.
+//
+// If no Runner is associated with ctx, FromContext returns a nil channel
+// (which blocks forever) and false.
+func FromContext(ctx context.Context) (<-chan struct{}, bool) {
+	if r, ok := ctx.Value(runnerKey{}).(*Runner); ok && r != nil {
+		return r.LameDuck(), true
+	}
+
+	return nil, false
+}
+
+// ConnsFromContext returns the ConnRegistry for the Runner associated with
+// ctx, or nil if there is none. Since a nil *ConnRegistry is valid, the
+// result may be used without checking.
+//
+//     release := lameduck.ConnsFromContext(ctx).Register(conn, sendGoAway)
+//     defer release()
+//
+func ConnsFromContext(ctx context.Context) *ConnRegistry {
+	if r, ok := ctx.Value(runnerKey{}).(*Runner); ok && r != nil {
+		return r.Conns()
+	}
+
+	return nil
+}
+
+// ReadyFromContext returns the ReadyFunc associated with ctx, which is used by
+// a Server implementing ReadyReporter to report readiness. The ReadyFunc is
+// bound to the call to Serve whose Context it came from; once that call
+// returns, it does nothing. If no ReadyFunc is associated with ctx, a no-op
+// ReadyFunc is returned. The result is never nil.
+func ReadyFromContext(ctx context.Context) ReadyFunc {
+	if f, ok := ctx.Value(readyKey{}).(ReadyFunc); ok && f != nil {
+		return f
+	}
+
+	return func() {}
+}
+
+// TriggerSignal returns the signal that triggered lame-duck mode if ctx is
+// (or is derived from) the Context passed to a Server's Shutdown method or to
+// a pre-shutdown HookFunction. Otherwise, it returns nil.
+func TriggerSignal(ctx context.Context) os.Signal {
+	sig, _ := ctx.Value(signalKey{}).(os.Signal)
+	return sig
+}
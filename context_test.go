@@ -0,0 +1,65 @@
+package lameduck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+type ctxServer struct {
+	*testServer
+	ctxs chan context.Context
+}
+
+func (cs *ctxServer) Serve(ctx context.Context) error {
+	cs.ctxs <- ctx
+	return cs.testServer.Serve(ctx)
+}
+
+func TestFromContext(t *testing.T) {
+	if ch, ok := FromContext(context.Background()); ok || ch != nil {
+		t.Errorf("FromContext(context.Background()) == (%v, %v); wanted (nil, false)", ch, ok)
+	}
+
+	ts := injectSignaller()
+	defer ts.revert()
+
+	tl := &testLogger{t.Logf}
+	svr := &ctxServer{newTestServer(tl, nil, nil, nil), make(chan context.Context, 1)}
+
+	r, err := NewRunner(svr, WithLogger(tl), Period(time.Second))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- r.Run(context.Background()) }()
+
+	ch, ok := FromContext(<-svr.ctxs)
+	if !ok {
+		t.Fatal("FromContext(<serve context>) returned false; wanted true")
+	}
+
+	select {
+	case <-ch:
+		t.Fatal("lame-duck channel closed before signal")
+	default:
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	ts.emit(unix.SIGTERM)
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("lame-duck channel not closed after signal")
+	}
+
+	svr.shutdown.finish()
+
+	if err := <-errs; err != nil {
+		t.Errorf("Run(ctx) == %v; wanted nil", err)
+	}
+}
@@ -0,0 +1,109 @@
+package lameduck
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"runtime/pprof"
+	"strings"
+)
+
+type dumpOption struct {
+	w       io.Writer
+	path    string
+	filters []string
+}
+
+// DumpGoroutines returns an Option that, when the lame-duck period expires,
+// writes a goroutine profile to w just before the Server's Close method is
+// called. This helps identify the requests (or locks) that kept the Server
+// from draining in time. If w is nil, the profile is logged instead.
+//
+// If any filters are provided, only those goroutines whose stack traces
+// contain at least one of the filters (e.g. a package path for handler
+// frames) are included.
+func DumpGoroutines(w io.Writer, filters ...string) Option {
+	return &dumpOption{w: w, filters: filters}
+}
+
+// DumpGoroutinesToFile is like DumpGoroutines except that the goroutine
+// profile is written to a file created (or truncated) at the given path at
+// the time lame-duck expires.
+func DumpGoroutinesToFile(path string, filters ...string) Option {
+	return &dumpOption{path: path, filters: filters}
+}
+
+func (o *dumpOption) set(r *Runner) {
+	r.dump = o
+}
+
+// dumpGoroutines writes a (filtered) goroutine profile as configured by the
+// DumpGoroutines Option.
+func (r *Runner) dumpGoroutines() {
+	if r.dump == nil {
+		return
+	}
+
+	dump, n, total := goroutineDump(r.dump.filters)
+
+	switch {
+	case r.dump.path != "":
+		if err := ioutil.WriteFile(r.dump.path, []byte(dump), 0644); err != nil {
+			r.logError("Cannot write goroutine dump", "path", r.dump.path, "error", err)
+			return
+		}
+		r.logWarn("Wrote goroutine dump", "path", r.dump.path, "goroutines", n, "total", total)
+
+	case r.dump.w != nil:
+		if _, err := io.WriteString(r.dump.w, dump); err != nil {
+			r.logError("Cannot write goroutine dump", "error", err)
+			return
+		}
+		r.logWarn("Wrote goroutine dump", "goroutines", n, "total", total)
+
+	default:
+		r.logWarn("Goroutine dump", "goroutines", n, "total", total, "dump", dump)
+	}
+}
+
+// goroutineDump returns the stack traces for all goroutines matching at least
+// one of the given filters (or all goroutines, if there are no filters) along
+// with the number of goroutines included and the total number found.
+func goroutineDump(filters []string) (string, int, int) {
+	var buf bytes.Buffer
+	pprof.Lookup("goroutine").WriteTo(&buf, 2)
+
+	blocks := strings.Split(strings.TrimSpace(buf.String()), "\n\n")
+
+	var sb strings.Builder
+	var n int
+
+	for _, b := range blocks {
+		if !matchesAny(b, filters) {
+			continue
+		}
+
+		if n > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintln(&sb, b)
+		n++
+	}
+
+	return sb.String(), n, len(blocks)
+}
+
+func matchesAny(s string, filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+
+	for _, f := range filters {
+		if strings.Contains(s, f) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,56 @@
+package lameduck
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func stuckHandler(ch chan struct{}) {
+	<-ch
+}
+
+func TestDumpGoroutines(t *testing.T) {
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	go stuckHandler(stuck)
+
+	ts := injectSignaller()
+	defer ts.revert()
+
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, nil, nil, nil)
+
+	var buf bytes.Buffer
+
+	r, err := NewRunner(svr, WithLogger(tl), Period(20*time.Millisecond), DumpGoroutines(&buf, "lameduck.stuckHandler"))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- r.Run(context.Background()) }()
+
+	<-r.Ready()
+	time.Sleep(10 * time.Millisecond)
+	ts.emit(unix.SIGTERM)
+
+	if err := <-errs; OutcomeOf(err) != Expired {
+		t.Fatalf("Run(ctx) == %#v; wanted expiry", err)
+	}
+
+	got := buf.String()
+
+	if !strings.Contains(got, "lameduck.stuckHandler") {
+		t.Errorf("goroutine dump missing stuckHandler frame:\n%s", got)
+	}
+
+	if n := strings.Count("\n"+got, "\ngoroutine "); n != 1 {
+		t.Errorf("goroutine dump has %d goroutines; wanted 1:\n%s", n, got)
+	}
+}
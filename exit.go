@@ -0,0 +1,121 @@
+package lameduck
+
+import (
+	"context"
+	"time"
+)
+
+// ExitCodes maps the Outcome of a call to Run into a process exit status; see
+// Main. An Outcome of Canceled uses the ShutdownError code.
+type ExitCodes struct {
+	Clean         int // Outcome: Clean
+	Expired       int // Outcome: Expired
+	ServeFailed   int // Outcome: ServeFailed (or an invalid configuration)
+	ShutdownError int // Outcome: ShutdownError or Canceled
+	Forced        int // Outcome: Forced
+}
+
+// DefaultExitCodes are the ExitCodes used by Main unless altered using the
+// WithExitCodes Option.
+var DefaultExitCodes = ExitCodes{
+	Clean:         0,
+	Expired:       1,
+	ServeFailed:   2,
+	ShutdownError: 3,
+	Forced:        4,
+}
+
+// Code returns the exit status for the given Outcome.
+func (ec ExitCodes) Code(o Outcome) int {
+	switch o {
+	case Clean:
+		return ec.Clean
+	case Expired:
+		return ec.Expired
+	case ServeFailed:
+		return ec.ServeFailed
+	case Forced:
+		return ec.Forced
+	default:
+		return ec.ShutdownError
+	}
+}
+
+// WithExitCodes returns an Option that alters the exit codes used by Main.
+func WithExitCodes(ec ExitCodes) Option {
+	return exitCodes(ec)
+}
+
+type exitCodes ExitCodes
+
+func (ec exitCodes) set(r *Runner) {
+	r.exitCodes = ExitCodes(ec)
+}
+
+// ExitDelay returns an Option that causes Main to wait for the given Duration
+// before exiting, allowing log shippers time to collect the final lines of
+// output. The Duration must not be negative.
+func ExitDelay(d time.Duration) Option {
+	return exitDelay(d)
+}
+
+type exitDelay time.Duration
+
+func (d exitDelay) set(r *Runner) {
+	r.exitDelay = time.Duration(d)
+}
+
+// Main is intended to be called from a program's main function. It runs svr
+// with coordinated lame-duck behavior (see Run), flushes the configured
+// logger (if it has a Flush method), optionally waits (see ExitDelay) and then
+// exits the process with a status reflecting the Outcome of Run (see
+// ExitCodes). Main never returns.
+//
+// If a Runner cannot be created with the given Options, the error is logged
+// and the process exits with the ServeFailed exit code. As far as they can be
+// determined, the logger and ExitCodes given by those Options are used.
+func Main(svr Server, options ...Option) {
+	r, err := newRunner(svr, options)
+	if err != nil {
+		// Resolve what we can of the configured logger and exit codes
+		cfg := &Runner{logger: StdLogger(nil), exitCodes: DefaultExitCodes}
+		for _, o := range options {
+			o.set(cfg)
+		}
+
+		if cfg.logger == nil {
+			cfg.logger = StdLogger(nil)
+		}
+
+		cfg.logger.Log(LevelError, "Cannot create lame-duck Runner", "error", err)
+		flushLogger(cfg.logger)
+		osExit(cfg.exitCodes.ServeFailed)
+		return
+	}
+
+	r.Main(context.Background())
+}
+
+// Main runs the receiver (see Run) then exits the process according to its
+// configured ExitCodes and ExitDelay.
+//
+// See the Main func for details.
+func (r *Runner) Main(ctx context.Context) {
+	err := r.Run(ctx)
+
+	o := OutcomeOf(err)
+	code := r.exitCodes.Code(o)
+
+	if o != Clean {
+		r.logError("Run failed", "error", err)
+	}
+	r.logInfo("Exiting", "outcome", o, "code", code)
+
+	flushLogger(r.logger)
+
+	if r.exitDelay > 0 {
+		time.Sleep(r.exitDelay)
+	}
+
+	osExit(code)
+}
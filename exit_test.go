@@ -0,0 +1,67 @@
+package lameduck
+
+import (
+	"testing"
+)
+
+func TestMainExit(t *testing.T) {
+	defer func() { osExit = osExitOrig }()
+
+	tl := &testLogger{t.Logf}
+
+	var flushed bool
+	fl := &FuncLogger{Infof: tl.Infof, Flush: func() { flushed = true }}
+
+	codes := DefaultExitCodes
+	codes.ServeFailed = 17
+
+	got := -1
+	osExit = func(code int) { got = code }
+
+	Main(newTestServer(tl, errServeFailed, nil, nil), WithStructuredLogger(fl), WithExitCodes(codes))
+
+	if got != 17 {
+		t.Errorf("Main exited with %d; wanted 17", got)
+	}
+
+	if !flushed {
+		t.Error("Main did not flush logger")
+	}
+
+	for label, bad := range map[string]Option{"Period": Period(-1), "ExitDelay": ExitDelay(-1)} {
+		got, flushed = -1, false
+		Main(newTestServer(tl, nil, nil, nil), WithStructuredLogger(fl), WithExitCodes(codes), bad)
+
+		if got != 17 {
+			t.Errorf("Main (with bad %s) exited with %d; wanted 17", label, got)
+		}
+
+		if !flushed {
+			t.Errorf("Main (with bad %s) did not flush logger", label)
+		}
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	ec := ExitCodes{Clean: 10, Expired: 11, ServeFailed: 12, ShutdownError: 13, Forced: 14}
+
+	cases := map[Outcome]int{
+		Clean:         10,
+		Expired:       11,
+		ServeFailed:   12,
+		ShutdownError: 13,
+		Canceled:      13,
+		Forced:        14,
+	}
+
+	for o, want := range cases {
+		if got := ec.Code(o); got != want {
+			t.Errorf("ec.Code(%v) == %d; wanted %d", o, got, want)
+		}
+	}
+
+	if got := OutcomeOf(&LameDuckError{Expired: true, CloseTimedOut: true, Err: ErrCloseTimeout}); got != Forced {
+		t.Errorf("OutcomeOf(<close timeout>) == %v; wanted %v", got, Forced)
+	}
+
+}
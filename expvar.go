@@ -0,0 +1,78 @@
+package lameduck
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+)
+
+var expvarMu sync.Mutex
+
+// PublishExpvar returns an Option that publishes the Runner's status under
+// the given name within the "lameduck" expvar Map (and thus, for processes
+// importing net/http/pprof or otherwise serving expvar.Handler, at
+// /debug/vars). Several Runners may be published in a single process, so
+// long as each uses a distinct name; a Runner published under an existing
+// name replaces the prior one.
+//
+// Each Runner's value includes its current State, the time lame-duck mode
+// was triggered and its deadline, the number of in-flight connections tracked
+// by its ConnRegistry, and the Report from its most recent call to Run.
+//
+// The Runner is published only once it has been successfully created. If the
+// "lameduck" expvar name is already in use by something other than an
+// expvar.Map, NewRunner returns an error.
+func PublishExpvar(name string) Option {
+	return expvarName(name)
+}
+
+type expvarName string
+
+func (n expvarName) set(r *Runner) {
+	r.expvarName = string(n)
+}
+
+// publishExpvar publishes the receiver under its configured expvar name, if
+// any.
+func (r *Runner) publishExpvar() error {
+	if r.expvarName == "" {
+		return nil
+	}
+
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	var m *expvar.Map
+
+	switch v := expvar.Get("lameduck").(type) {
+	case nil:
+		m = expvar.NewMap("lameduck")
+	case *expvar.Map:
+		m = v
+	default:
+		return errors.New(`expvar name "lameduck" is already in use`)
+	}
+
+	m.Set(r.expvarName, expvar.Func(r.expvarValue))
+	return nil
+}
+
+func (r *Runner) expvarValue() interface{} {
+	rp := r.report()
+
+	v := map[string]interface{}{
+		"state": r.State().String(),
+		"in_flight": map[string]int{
+			"tracked":   r.conns.Len(),
+			"keepalive": r.conns.keepAliveLen(),
+		},
+		"last_report": r.LastReport(),
+	}
+
+	if !rp.Triggered.IsZero() {
+		v["triggered"] = rp.Triggered
+		v["deadline"] = rp.Deadline
+	}
+
+	return v
+}
@@ -0,0 +1,75 @@
+package lameduck
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	ts := injectSignaller()
+	defer ts.revert()
+
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, nil, nil, nil)
+	svr.shutdown.finish()
+
+	r, err := NewRunner(svr, WithLogger(tl), PublishExpvar("primary"))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	if _, err := NewRunner(newTestServer(tl, nil, nil, nil), PublishExpvar("secondary")); err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	if _, err := NewRunner(newTestServer(tl, nil, nil, nil), PublishExpvar("invalid"), Period(0)); err == nil {
+		t.Fatal("NewRunner with invalid Period returned nil error")
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- r.Run(context.Background()) }()
+
+	<-r.Ready()
+	time.Sleep(10 * time.Millisecond)
+	ts.emit(unix.SIGTERM)
+
+	if err := <-errs; err != nil {
+		t.Fatalf("Run(ctx) == %v; wanted nil", err)
+	}
+
+	var got map[string]struct {
+		State      string
+		Triggered  *time.Time
+		InFlight   map[string]int `json:"in_flight"`
+		LastReport *Report        `json:"last_report"`
+	}
+
+	if err := json.Unmarshal([]byte(expvar.Get("lameduck").String()), &got); err != nil {
+		t.Fatalf("cannot decode expvar value: %v", err)
+	}
+
+	p, ok := got["primary"]
+	switch {
+	case !ok:
+		t.Fatal(`expvar "lameduck" map has no "primary" key`)
+	case p.State != "STOPPED":
+		t.Errorf("primary state == %q; wanted %q", p.State, "STOPPED")
+	case p.Triggered == nil:
+		t.Error("primary has no trigger time")
+	case p.LastReport == nil || p.LastReport.Signal != unix.SIGTERM.String():
+		t.Errorf("primary last_report == %+v; wanted Signal %q", p.LastReport, unix.SIGTERM)
+	}
+
+	if _, ok := got["invalid"]; ok {
+		t.Error(`expvar "lameduck" map has "invalid" key for a Runner that failed validation`)
+	}
+
+	if s, ok := got["secondary"]; !ok || s.State != "NOT_STARTED" || s.LastReport != nil {
+		t.Errorf(`secondary == %+v (found:%v); wanted State "NOT_STARTED" with no LastReport`, s, ok)
+	}
+}
@@ -0,0 +1,122 @@
+package lameduck
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// RegisterFlags defines the following flags in fs and returns a slice of
+// Options reflecting their values:
+//
+//     --lameduck-period         The lame-duck period (see Period)
+//     --lameduck-signals        The signals triggering lame-duck (see Signals)
+//     --lameduck-drain-delay    Delay before Shutdown (see DrainDelay)
+//     --lameduck-close-timeout  Time allowed for Close (see CloseTimeout)
+//     --lameduck-stop-timeout   Time allowed per OnStop closer (see StopTimeout)
+//
+// Signals are given as a comma separated list of names, with or without the
+// "SIG" prefix, or numbers (e.g. "TERM,INT,USR1").
+//
+// The returned Options read their flag values when the Runner is created, so
+// they may be obtained before fs is parsed. Only those flags explicitly set
+// on the command line are applied; others leave the Runner's configuration
+// (including that from any preceding Options) unchanged.
+func RegisterFlags(fs *pflag.FlagSet) []Option {
+	fo := newFlagOptions()
+
+	for _, f := range fo.flags() {
+		fs.Var(f.value, f.name, f.usage)
+	}
+
+	fo.visit = func(fn func(name string)) {
+		fs.Visit(func(f *pflag.Flag) { fn(f.Name) })
+	}
+
+	return []Option{fo}
+}
+
+// RegisterGoFlags is like RegisterFlags except that it defines its flags in a
+// FlagSet from the standard library's flag package.
+func RegisterGoFlags(fs *flag.FlagSet) []Option {
+	fo := newFlagOptions()
+
+	for _, f := range fo.flags() {
+		fs.Var(f.value, f.name, f.usage)
+	}
+
+	fo.visit = func(fn func(name string)) {
+		fs.Visit(func(f *flag.Flag) { fn(f.Name) })
+	}
+
+	return []Option{fo}
+}
+
+type flagOptions struct {
+	period       durationValue
+	drainDelay   durationValue
+	closeTimeout durationValue
+	stopTimeout  durationValue
+	signals      SignalList
+
+	// visit calls fn with the name of each flag that has been set.
+	visit func(fn func(name string))
+}
+
+func newFlagOptions() *flagOptions {
+	return &flagOptions{
+		period:  durationValue(defaultPeriod),
+		signals: SignalList(defaultSignals),
+	}
+}
+
+type flagDef struct {
+	name  string
+	value pflag.Value // also implements flag.Value
+	usage string
+}
+
+func (fo *flagOptions) flags() []flagDef {
+	return []flagDef{
+		{"lameduck-period", &fo.period, "lame-duck period"},
+		{"lameduck-signals", &fo.signals, "comma separated list of signals that trigger lame-duck mode"},
+		{"lameduck-drain-delay", &fo.drainDelay, "delay between the lame-duck trigger and server shutdown"},
+		{"lameduck-close-timeout", &fo.closeTimeout, "time allowed for server Close after lame-duck expires (0 for no limit)"},
+		{"lameduck-stop-timeout", &fo.stopTimeout, "time allowed for each stop closer (0 for no limit)"},
+	}
+}
+
+func (fo *flagOptions) set(r *Runner) {
+	fo.visit(func(name string) {
+		switch name {
+		case "lameduck-period":
+			r.period = time.Duration(fo.period)
+		case "lameduck-signals":
+			r.signals = []os.Signal(fo.signals)
+		case "lameduck-drain-delay":
+			r.drainDelay = time.Duration(fo.drainDelay)
+		case "lameduck-close-timeout":
+			r.closeTimeout = time.Duration(fo.closeTimeout)
+		case "lameduck-stop-timeout":
+			r.stopTimeout = time.Duration(fo.stopTimeout)
+		}
+	})
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+type durationValue time.Duration
+
+func (d *durationValue) String() string { return time.Duration(*d).String() }
+func (d *durationValue) Type() string   { return "duration" }
+
+func (d *durationValue) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
@@ -0,0 +1,81 @@
+package lameduck
+
+import (
+	"flag"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/sys/unix"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	args := []string{
+		"--lameduck-period=10s",
+		"--lameduck-signals=TERM,SIGUSR1,2",
+		"--lameduck-drain-delay=2s",
+		"--lameduck-close-timeout=5s",
+		"--lameduck-stop-timeout=1s",
+	}
+
+	pfs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	pOpts := RegisterFlags(pfs)
+
+	gfs := flag.NewFlagSet("test", flag.ContinueOnError)
+	gOpts := RegisterGoFlags(gfs)
+
+	if err := pfs.Parse(args); err != nil {
+		t.Fatalf("pflag Parse(%q): %v", args, err)
+	}
+
+	if err := gfs.Parse(args); err != nil {
+		t.Fatalf("flag Parse(%q): %v", args, err)
+	}
+
+	for label, opts := range map[string][]Option{"pflag": pOpts, "flag": gOpts} {
+		r, err := NewRunner(newTestServer(nil, nil, nil, nil), opts...)
+		if err != nil {
+			t.Fatalf("%s: cannot create Runner: %v", label, err)
+		}
+
+		if r.period != 10*time.Second || r.drainDelay != 2*time.Second || r.closeTimeout != 5*time.Second || r.stopTimeout != time.Second {
+			t.Errorf("%s: durations == (%v, %v, %v, %v); wanted (10s, 2s, 5s, 1s)", label, r.period, r.drainDelay, r.closeTimeout, r.stopTimeout)
+		}
+
+		want := []os.Signal{unix.SIGTERM, unix.SIGUSR1, unix.SIGINT}
+		if !reflect.DeepEqual(r.signals, want) {
+			t.Errorf("%s: signals == %v; wanted %v", label, r.signals, want)
+		}
+	}
+
+	// Flags that were not given must not override other Options.
+	ufs := pflag.NewFlagSet("unset", pflag.ContinueOnError)
+	uOpts := RegisterFlags(ufs)
+
+	if err := ufs.Parse([]string{"--lameduck-drain-delay=2s"}); err != nil {
+		t.Fatalf("pflag Parse: %v", err)
+	}
+
+	r, err := NewRunner(newTestServer(nil, nil, nil, nil), append([]Option{Period(time.Minute), Signals(unix.SIGUSR2)}, uOpts...)...)
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	want := []os.Signal{unix.SIGUSR2}
+	if r.period != time.Minute || r.drainDelay != 2*time.Second || !reflect.DeepEqual(r.signals, want) {
+		t.Errorf("(period, drainDelay, signals) == (%v, %v, %v); wanted (1m0s, 2s, %v)", r.period, r.drainDelay, r.signals, want)
+	}
+
+	if got, want := pfs.Lookup("lameduck-signals").DefValue, "INT,TERM"; got != want {
+		t.Errorf("--lameduck-signals default == %q; wanted %q", got, want)
+	}
+
+	bfs := pflag.NewFlagSet("bad", pflag.ContinueOnError)
+	RegisterFlags(bfs)
+
+	if err := bfs.Parse([]string{"--lameduck-signals=TERM,BOGUS"}); err == nil {
+		t.Error("Parse(--lameduck-signals=TERM,BOGUS) returned nil error")
+	}
+}
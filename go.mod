@@ -6,5 +6,4 @@ require (
 	github.com/spf13/pflag v1.0.3
 	golang.org/x/sync v0.0.0-20201207232520-09787c993a3a
 	golang.org/x/sys v0.1.0
-	toolman.org/base/log/v2 v2.1.0
 )
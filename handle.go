@@ -0,0 +1,129 @@
+package lameduck
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Handle is returned by Runner.Start for controlling and observing a Runner
+// executing in the background.
+type Handle struct {
+	trigger chan struct{}
+	stop    sync.Once
+	done    chan struct{}
+	err     error
+}
+
+// Start executes the receiver's Server in the background, as if by calling Run
+// in its own goroutine, and returns a Handle for controlling it. Start returns
+// once the receiver is Ready or, should Run return before then (e.g. because
+// the Server failed immediately), the error returned by Run.
+//
+// Unless the Server implements ReadyReporter, it is considered Ready as soon
+// as its Serve method is called, so a Server failing immediately (e.g. one
+// unable to listen on its address) may still be reported as started; its
+// failure is then only reported by the returned Handle. Implement
+// ReadyReporter for a reliable check or, failing that, use the StartGrace
+// Option.
+//
+// As with Run, Start returns ErrAlreadyRunning if the receiver is already
+// running.
+func (r *Runner) Start(ctx context.Context) (*Handle, error) {
+	if err := r.beginRun(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	ready := r.ready
+	h := &Handle{trigger: r.trigger, done: make(chan struct{})}
+	r.mu.Unlock()
+
+	go func() {
+		defer close(h.done)
+		h.err = r.run(ctx)
+	}()
+
+	select {
+	case <-ready:
+	case <-h.done:
+		return h.started()
+	}
+
+	if r.startGrace > 0 && !r.reportsReady() {
+		timer := time.NewTimer(r.startGrace)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-h.done:
+			return h.started()
+		}
+	}
+
+	return h, nil
+}
+
+// started returns the values to be returned by Start should Run complete
+// before Start returns.
+func (h *Handle) started() (*Handle, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	return h, nil
+}
+
+// StartGrace returns an Option that causes Start, for a Server not
+// implementing ReadyReporter, to wait for the given Duration after calling
+// Serve before reporting success. Should Run return with an error in the
+// meantime, Start returns that error instead. The default, zero, imposes no
+// wait.
+func StartGrace(d time.Duration) Option {
+	return startGrace(d)
+}
+
+type startGrace time.Duration
+
+func (d startGrace) set(r *Runner) {
+	r.startGrace = time.Duration(d)
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// Stop triggers lame-duck mode, exactly as if one of the Runner's configured
+// signals had been received, then waits for Run to complete. If ctx is done
+// first, its error is returned; otherwise, Stop returns the error returned by
+// Run. Stop may be called more than once; if lame-duck mode is already
+// underway, Stop merely waits.
+func (h *Handle) Stop(ctx context.Context) error {
+	h.stop.Do(func() { close(h.trigger) })
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-h.done:
+		return h.err
+	}
+}
+
+// Wait waits for Run to complete and returns its error.
+func (h *Handle) Wait() error {
+	<-h.done
+	return h.err
+}
+
+// Done returns a channel that is closed once Run has completed.
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Err returns the error returned by Run, or nil if Run has yet to complete.
+func (h *Handle) Err() error {
+	select {
+	case <-h.done:
+		return h.err
+	default:
+		return nil
+	}
+}
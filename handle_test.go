@@ -0,0 +1,74 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStart(t *testing.T) {
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, nil, nil, nil)
+	svr.shutdown.finish()
+
+	r, err := NewRunner(svr, WithLogger(tl), Period(time.Second))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	h, err := r.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start(ctx): %v", err)
+	}
+
+	if _, err := r.Start(context.Background()); err != ErrAlreadyRunning {
+		t.Errorf("second Start(ctx) == %v; wanted %v", err, ErrAlreadyRunning)
+	}
+
+	if err := h.Err(); err != nil {
+		t.Errorf("h.Err() == %v before Stop; wanted nil", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := h.Stop(ctx); err != nil {
+		t.Fatalf("h.Stop(ctx) == %v; wanted nil", err)
+	}
+
+	select {
+	case <-h.Done():
+	default:
+		t.Errorf("h.Done() not closed after Stop")
+	}
+
+	if err := h.Wait(); err != nil {
+		t.Errorf("h.Wait() == %v; wanted nil", err)
+	}
+
+	if got, want := r.LastReport().Signal, stopTrigger.String(); got != want {
+		t.Errorf("Report.Signal == %q; wanted %q", got, want)
+	}
+}
+
+func TestStartFailed(t *testing.T) {
+	errServe := errors.New("cannot listen")
+
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, errServe, nil, nil)
+
+	r, err := NewRunner(svr, WithLogger(tl), StartGrace(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	h, err := r.Start(context.Background())
+	if h != nil {
+		t.Errorf("Start(ctx) returned non-nil Handle")
+	}
+
+	if want := (&LameDuckError{Failed: true, Err: errServe}); !want.isEqual(err) {
+		t.Errorf("Start(ctx) == %#v; wanted %#v", err, want)
+	}
+}
@@ -0,0 +1,253 @@
+package lameduck
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Level is the severity of a log message.
+type Level int
+
+const (
+	LevelDebug Level = iota // Diagnostic detail; normally not shown
+	LevelInfo               // Normal lifecycle events
+	LevelWarn               // Unexpected, but recoverable, events
+	LevelError              // Failures
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// StructuredLogger is the interface implemented by leveled, key-value
+// loggers. Each message is accompanied by zero or more alternating keys and
+// values; keys are always strings.
+//
+// All lifecycle messages logged by a Runner include its current "state" and,
+// while running, the time "elapsed" since Run was called. Other fields (such
+// as "signal", "period" or "error") are added where relevant.
+//
+// Adapters are provided for the standard library's log package (StdLogger),
+// log/slog (SlogLogger) and glog-style printf functions (FuncLogger).
+type StructuredLogger interface {
+	Log(level Level, msg string, keyvals ...interface{})
+}
+
+// WithStructuredLogger returns an Option that alters this package's logging
+// facility to the provided StructuredLogger. The default is StdLogger(nil).
+// To prevent all logging, use WithoutLogger.
+func WithStructuredLogger(l StructuredLogger) Option {
+	return &loggerOption{l}
+}
+
+// Logger is the interface needed for the WithLogger Option.
+type Logger interface {
+	Infof(string, ...interface{})
+}
+
+// WithLogger returns an Option that alters this package's logging facility
+// to the provided Logger. If l also has Warningf or Errorf methods (as do
+// 'github.com/golang/glog' and 'toolman.org/base/log/v2'), these are used for
+// messages of the corresponding Level; all other messages are logged using
+// its Infof method. Debug messages are discarded. If l has a Flush method, it
+// is called by Main before the process exits.
+//
+// To prevent all logging, use WithoutLogger.
+func WithLogger(l Logger) Option {
+	if l == nil {
+		return WithoutLogger()
+	}
+
+	fl := &FuncLogger{Infof: l.Infof}
+
+	if w, ok := l.(interface{ Warningf(string, ...interface{}) }); ok {
+		fl.Warningf = w.Warningf
+	}
+
+	if e, ok := l.(interface{ Errorf(string, ...interface{}) }); ok {
+		fl.Errorf = e.Errorf
+	}
+
+	if f, ok := l.(interface{ Flush() }); ok {
+		fl.Flush = f.Flush
+	}
+
+	return &loggerOption{fl}
+}
+
+// WithoutLogger returns an option the disables all logging from this package.
+func WithoutLogger() Option {
+	return &loggerOption{}
+}
+
+type loggerOption struct {
+	logger StructuredLogger
+}
+
+func (o *loggerOption) set(r *Runner) {
+	if r.logger = o.logger; r.logger == nil {
+		r.logger = nopLogger{}
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(Level, string, ...interface{}) {}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// StdLogger returns a StructuredLogger that writes messages of LevelInfo and
+// above to l (or, if l is nil, to the standard library's default logger).
+// Each message is prefixed by its Level and followed by its fields in
+// key=value form.
+func StdLogger(l *log.Logger) StructuredLogger {
+	return &stdLogger{l}
+}
+
+type stdLogger struct {
+	logger *log.Logger
+}
+
+func (sl *stdLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	if level < LevelInfo {
+		return
+	}
+
+	line := level.String() + " " + formatMessage(msg, keyvals)
+
+	if sl.logger == nil {
+		log.Print(line)
+	} else {
+		sl.logger.Print(line)
+	}
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// FuncLogger is a StructuredLogger that adapts a set of printf-style functions
+// -- such as those provided by 'github.com/golang/glog' or
+// 'toolman.org/base/log/v2' -- for use by this package:
+//
+//     lameduck.WithStructuredLogger(&lameduck.FuncLogger{
+//         Infof:    log.Infof,
+//         Warningf: log.Warningf,
+//         Errorf:   log.Errorf,
+//     })
+//
+// Fields are appended to each message in key=value form. Messages for a Level
+// whose function is nil are passed to the function for the next lower Level
+// (down to Infof); if Infof is also nil (or for LevelDebug, when Debugf is
+// nil) the message is discarded.
+//
+// If set, Flush is called by Main before the process exits.
+type FuncLogger struct {
+	Debugf   func(string, ...interface{})
+	Infof    func(string, ...interface{})
+	Warningf func(string, ...interface{})
+	Errorf   func(string, ...interface{})
+	Flush    func()
+}
+
+// Log implements StructuredLogger.
+func (fl *FuncLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	var f func(string, ...interface{})
+
+	switch {
+	case level <= LevelDebug:
+		f = fl.Debugf
+	case level >= LevelError && fl.Errorf != nil:
+		f = fl.Errorf
+	case level >= LevelWarn && fl.Warningf != nil:
+		f = fl.Warningf
+	default:
+		f = fl.Infof
+	}
+
+	if f != nil {
+		f("%s", formatMessage(msg, keyvals))
+	}
+}
+
+// flushLogger flushes l, if it supports flushing.
+func flushLogger(l StructuredLogger) {
+	switch l := l.(type) {
+	case *FuncLogger:
+		if l.Flush != nil {
+			l.Flush()
+		}
+
+	case interface{ Flush() }:
+		l.Flush()
+	}
+}
+
+// formatMessage renders msg followed by keyvals in key=value form.
+func formatMessage(msg string, keyvals []interface{}) string {
+	var sb strings.Builder
+
+	sb.WriteString(msg)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = "(MISSING)"
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+
+		fmt.Fprintf(&sb, " %v=%s", keyvals[i], formatValue(v))
+	}
+
+	return sb.String()
+}
+
+func formatValue(v interface{}) string {
+	var s string
+
+	switch v := v.(type) {
+	case time.Time:
+		s = v.Format(time.RFC3339Nano)
+	case error:
+		s = v.Error()
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return s
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+func (r *Runner) logDebug(msg string, keyvals ...interface{}) { r.log(LevelDebug, msg, keyvals) }
+func (r *Runner) logInfo(msg string, keyvals ...interface{})  { r.log(LevelInfo, msg, keyvals) }
+func (r *Runner) logWarn(msg string, keyvals ...interface{})  { r.log(LevelWarn, msg, keyvals) }
+func (r *Runner) logError(msg string, keyvals ...interface{}) { r.log(LevelError, msg, keyvals) }
+
+// log sends msg to the receiver's logger, adding the common "state" and
+// "elapsed" fields. It must not be called while holding r.mu.
+func (r *Runner) log(level Level, msg string, keyvals []interface{}) {
+	kv := make([]interface{}, 0, len(keyvals)+4)
+	kv = append(kv, keyvals...)
+	kv = append(kv, "state", r.State())
+
+	if started := r.report().Started; !started.IsZero() {
+		kv = append(kv, "elapsed", time.Since(started).Round(time.Microsecond))
+	}
+
+	r.logger.Log(level, msg, kv...)
+}
@@ -0,0 +1,71 @@
+package lameduck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestFormatMessage(t *testing.T) {
+	got := formatMessage("Received signal", []interface{}{
+		"signal", "terminated",
+		"period", 3 * time.Second,
+		"error", errors.New("bad thing"),
+		"empty", "",
+		"odd",
+	})
+
+	want := `Received signal signal=terminated period=3s error="bad thing" empty="" odd=(MISSING)`
+
+	if got != want {
+		t.Errorf("formatMessage(...) == %q; wanted %q", got, want)
+	}
+}
+
+func TestFuncLogger(t *testing.T) {
+	var got []string
+
+	mkf := func(prefix string) func(string, ...interface{}) {
+		return func(f string, a ...interface{}) { got = append(got, prefix+fmt.Sprintf(f, a...)) }
+	}
+
+	fl := &FuncLogger{Infof: mkf("I:"), Errorf: mkf("E:")}
+
+	fl.Log(LevelDebug, "debug")
+	fl.Log(LevelInfo, "info", "k", 1)
+	fl.Log(LevelWarn, "warn")
+	fl.Log(LevelError, "error")
+
+	want := []string{"I:info k=1", "I:warn", "E:error"}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("FuncLogger output == %q; wanted %q", got, want)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	sl := StdLogger(log.New(&buf, "", 0))
+
+	sl.Log(LevelDebug, "hidden")
+	sl.Log(LevelWarn, "Lame-duck period has expired", "period", time.Second)
+
+	if got, want := buf.String(), "WARN Lame-duck period has expired period=1s\n"; got != want {
+		t.Errorf("StdLogger output == %q; wanted %q", got, want)
+	}
+}
+
+func TestWithNilLogger(t *testing.T) {
+	r, err := NewRunner(newTestServer(nil, nil, nil, nil), WithLogger(nil))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	if _, ok := r.logger.(nopLogger); !ok {
+		t.Errorf("WithLogger(nil) produced a %T; wanted nopLogger", r.logger)
+	}
+}
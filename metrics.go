@@ -0,0 +1,370 @@
+package lameduck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Outcome describes how a call to Run concluded.
+type Outcome int
+
+const (
+	Clean         Outcome = iota // The Server was shut down within the lame-duck period
+	Expired                      // The lame-duck period expired and the Server was closed
+	ShutdownError                // The Server's Shutdown method returned an error
+	ServeFailed                  // The Server (or a goroutine started with Runner.Go) failed
+	Canceled                     // Run's Context was canceled before lame-duck mode began
+	Forced                       // The Server's Close method exceeded its CloseTimeout
+)
+
+var outcomes = []Outcome{Clean, Expired, ShutdownError, ServeFailed, Canceled, Forced}
+
+func (o Outcome) String() string {
+	switch o {
+	case Clean:
+		return "clean"
+	case Expired:
+		return "expired"
+	case ShutdownError:
+		return "shutdown_error"
+	case ServeFailed:
+		return "serve_failed"
+	case Canceled:
+		return "canceled"
+	case Forced:
+		return "forced"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (o *Outcome) UnmarshalText(text []byte) error {
+	for _, oc := range outcomes {
+		if oc.String() == string(text) {
+			*o = oc
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown outcome %q", text)
+}
+
+// OutcomeOf returns the Outcome corresponding to an error returned by Run.
+func OutcomeOf(err error) Outcome {
+	if err == nil {
+		return Clean
+	}
+
+	lde, ok := err.(*LameDuckError)
+	switch {
+	case !ok || lde == nil:
+		return ShutdownError
+	case lde.Failed:
+		return ServeFailed
+	case lde.CloseTimedOut:
+		return Forced
+	case lde.Expired:
+		return Expired
+	case lde.Err == context.Canceled:
+		return Canceled
+	case lde.Err == nil && len(lde.StopErrors) == 0:
+		return Clean
+	default:
+		return ShutdownError
+	}
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// MetricsSink is the interface implemented by types that receive metrics
+// describing a Runner's lifecycle. See the WithMetrics Option.
+//
+// The Metrics type provided by this package is a MetricsSink that exports its
+// values in Prometheus text format; other implementations may be used to
+// adapt these values to other metrics backends.
+type MetricsSink interface {
+	// SetState is called each time the Runner's State changes.
+	SetState(State)
+
+	// ObserveRun is called with the Outcome of each call to Run.
+	ObserveRun(Outcome)
+
+	// ObserveShutdown is called with the time taken by the Server's Shutdown
+	// method.
+	ObserveShutdown(time.Duration)
+
+	// ObserveHook is called with the time taken by a named hook. The names
+	// used are "pre_shutdown" (see WithPreShutdownHook) and "stop" (see
+	// Runner.OnStop).
+	ObserveHook(name string, d time.Duration)
+
+	// ObserveTriggerToExit is called with the time elapsed between the start
+	// of lame-duck mode and the return of Run.
+	ObserveTriggerToExit(time.Duration)
+
+	// ObserveShed is called with the number of connections notified and
+	// closed while shedding connections; see ShedConnections.
+	ObserveShed(notified, closed int)
+}
+
+// WithMetrics returns an Option that reports the Runner's lifecycle metrics
+// to the given MetricsSink.
+func WithMetrics(s MetricsSink) Option {
+	return &metricsOption{s}
+}
+
+type metricsOption struct {
+	sink MetricsSink
+}
+
+func (o *metricsOption) set(r *Runner) {
+	if r.metrics = o.sink; r.metrics == nil {
+		r.metrics = nopSink{}
+	}
+}
+
+type nopSink struct{}
+
+func (nopSink) SetState(State)                     {}
+func (nopSink) ObserveRun(Outcome)                 {}
+func (nopSink) ObserveShutdown(time.Duration)      {}
+func (nopSink) ObserveHook(string, time.Duration)  {}
+func (nopSink) ObserveTriggerToExit(time.Duration) {}
+func (nopSink) ObserveShed(int, int)               {}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// DefaultBuckets are the histogram bucket boundaries (in seconds) used by
+// NewMetrics.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Metrics is a dependency-free MetricsSink that also implements http.Handler
+// to export its values in Prometheus text exposition format. The following
+// metrics are exported:
+//
+//     lameduck_state                      gauge     (labeled by state)
+//     lameduck_runs_total                 counter   (labeled by outcome)
+//     lameduck_shutdown_duration_seconds  histogram
+//     lameduck_hook_duration_seconds      histogram (labeled by hook)
+//     lameduck_trigger_to_exit_seconds    histogram
+//     lameduck_shed_connections_total     counter   (labeled by action)
+//
+type Metrics struct {
+	mu       sync.Mutex
+	buckets  []float64
+	state    State
+	runs     map[Outcome]uint64
+	shutdown *histogram
+	hooks    map[string]*histogram
+	exit     *histogram
+	shed     map[string]uint64
+}
+
+var _ MetricsSink = (*Metrics)(nil)
+
+// NewMetrics returns a new Metrics using the given histogram bucket
+// boundaries (in seconds). If no buckets are provided, DefaultBuckets is
+// used.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Metrics{
+		buckets:  buckets,
+		state:    NotStarted,
+		runs:     make(map[Outcome]uint64),
+		shutdown: newHistogram(buckets),
+		hooks:    make(map[string]*histogram),
+		exit:     newHistogram(buckets),
+		shed:     make(map[string]uint64),
+	}
+}
+
+// SetState implements MetricsSink.
+func (m *Metrics) SetState(s State) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = s
+}
+
+// ObserveRun implements MetricsSink.
+func (m *Metrics) ObserveRun(o Outcome) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[o]++
+}
+
+// ObserveShutdown implements MetricsSink.
+func (m *Metrics) ObserveShutdown(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shutdown.observe(d)
+}
+
+// ObserveHook implements MetricsSink.
+func (m *Metrics) ObserveHook(name string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.hooks[name]
+	if !ok {
+		h = newHistogram(m.buckets)
+		m.hooks[name] = h
+	}
+
+	h.observe(d)
+}
+
+// ObserveTriggerToExit implements MetricsSink.
+func (m *Metrics) ObserveTriggerToExit(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exit.observe(d)
+}
+
+// ObserveShed implements MetricsSink.
+func (m *Metrics) ObserveShed(notified, closed int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shed["notified"] += uint64(notified)
+	m.shed["closed"] += uint64(closed)
+}
+
+// ServeHTTP implements http.Handler by writing the receiver's metrics in
+// Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the receiver's metrics to w in Prometheus text exposition
+// format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+
+	cw.printf("# HELP lameduck_state Current lame-duck state (1 for the current state, 0 otherwise).\n")
+	cw.printf("# TYPE lameduck_state gauge\n")
+	for _, s := range states {
+		var v int
+		if s == m.state {
+			v = 1
+		}
+		cw.printf("lameduck_state{state=%q} %d\n", s.String(), v)
+	}
+
+	cw.printf("# HELP lameduck_runs_total Number of completed runs by outcome.\n")
+	cw.printf("# TYPE lameduck_runs_total counter\n")
+	for _, o := range outcomes {
+		cw.printf("lameduck_runs_total{outcome=%q} %d\n", o.String(), m.runs[o])
+	}
+
+	cw.printf("# HELP lameduck_shutdown_duration_seconds Time taken by the Server's Shutdown method.\n")
+	cw.printf("# TYPE lameduck_shutdown_duration_seconds histogram\n")
+	m.shutdown.write(cw, "lameduck_shutdown_duration_seconds", "")
+
+	cw.printf("# HELP lameduck_hook_duration_seconds Time taken by lame-duck hooks.\n")
+	cw.printf("# TYPE lameduck_hook_duration_seconds histogram\n")
+	names := make([]string, 0, len(m.hooks))
+	for n := range m.hooks {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		m.hooks[n].write(cw, "lameduck_hook_duration_seconds", fmt.Sprintf("hook=%q", n))
+	}
+
+	cw.printf("# HELP lameduck_trigger_to_exit_seconds Time from the start of lame-duck mode until Run returns.\n")
+	cw.printf("# TYPE lameduck_trigger_to_exit_seconds histogram\n")
+	m.exit.write(cw, "lameduck_trigger_to_exit_seconds", "")
+
+	cw.printf("# HELP lameduck_shed_connections_total Number of connections shed by action.\n")
+	cw.printf("# TYPE lameduck_shed_connections_total counter\n")
+	for _, a := range []string{"closed", "notified"} {
+		cw.printf("lameduck_shed_connections_total{action=%q} %d\n", a, m.shed[a])
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+
+	return cw.n, cw.err
+}
+
+type histogram struct {
+	bounds []float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	v := d.Seconds()
+
+	for i, b := range h.bounds {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(cw *countWriter, name, labels string) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+
+	for i, b := range h.bounds {
+		cw.printf("%s_bucket{%s%sle=%q} %d\n", name, labels, sep, strconv.FormatFloat(b, 'g', -1, 64), h.counts[i])
+	}
+	cw.printf("%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.count)
+
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+
+	cw.printf("%s_sum%s %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	cw.printf("%s_count%s %d\n", name, labels, h.count)
+}
+
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) printf(format string, args ...interface{}) {
+	if cw.err != nil {
+		return
+	}
+
+	n, err := fmt.Fprintf(cw.w, format, args...)
+	cw.n += int64(n)
+	cw.err = err
+}
@@ -0,0 +1,84 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestOutcomeOf(t *testing.T) {
+	cases := []struct {
+		err  error
+		want Outcome
+	}{
+		{nil, Clean},
+		{&LameDuckError{Expired: true}, Expired},
+		{&LameDuckError{Failed: true, Err: errServeFailed}, ServeFailed},
+		{&LameDuckError{Err: errShutdownFailed}, ShutdownError},
+		{&LameDuckError{Err: context.Canceled}, Canceled},
+		{&LameDuckError{StopErrors: []error{errShutdownFailed}}, ShutdownError},
+		{errors.New("other"), ShutdownError},
+	}
+
+	for _, tc := range cases {
+		if got := OutcomeOf(tc.err); got != tc.want {
+			t.Errorf("OutcomeOf(%#v) == %v; wanted %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	ts := injectSignaller()
+	defer ts.revert()
+
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, nil, nil, nil)
+	svr.shutdown.finish()
+
+	m := NewMetrics(0.01, 1)
+
+	r, err := NewRunner(svr, WithLogger(tl), WithMetrics(m), WithPreShutdownHook(func(context.Context) error { return nil }))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- r.Run(context.Background()) }()
+
+	<-r.Ready()
+	time.Sleep(10 * time.Millisecond)
+	ts.emit(unix.SIGTERM)
+
+	if err := <-errs; err != nil {
+		t.Fatalf("Run(ctx) == %v; wanted nil", err)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, nil)
+	got := rec.Body.String()
+
+	for _, want := range []string{
+		`lameduck_state{state="STOPPED"} 1`,
+		`lameduck_state{state="RUNNING"} 0`,
+		`lameduck_runs_total{outcome="clean"} 1`,
+		`lameduck_runs_total{outcome="expired"} 0`,
+		`lameduck_shutdown_duration_seconds_bucket{le="1"} 1`,
+		`lameduck_shutdown_duration_seconds_bucket{le="+Inf"} 1`,
+		`lameduck_shutdown_duration_seconds_count 1`,
+		`lameduck_hook_duration_seconds_count{hook="pre_shutdown"} 1`,
+		`lameduck_trigger_to_exit_seconds_count 1`,
+	} {
+		if !strings.Contains(got, want+"\n") {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+
+	if t.Failed() {
+		t.Logf("metrics output:\n%s", got)
+	}
+}
@@ -43,36 +43,6 @@ func (s signals) set(r *Runner) {
 
 // - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
 
-// Logger is the interface needed for the WithLogger Option.
-type Logger interface {
-	Infof(string, ...interface{})
-}
-
-type loggerOption struct {
-	logger Logger
-}
-
-// WithLogger returns an Option that alters this package's logging facility
-// to the provided Logger. Note, the default Logger is one derived from
-// 'github.com/golang/glog'. To prevent all logging, use WithoutLogger.
-func WithLogger(l Logger) Option {
-	return &loggerOption{l}
-}
-
-// WithoutLogger returns an option the disables all logging from this package.
-func WithoutLogger() Option {
-	return &loggerOption{}
-}
-
-func (o *loggerOption) set(r *Runner) {
-	if r.logf = o.logger.Infof; r.logf == nil {
-		// a "silent" logger
-		r.logf = func(string, ...interface{}) {}
-	}
-}
-
-// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
-
 // HookFunction is a function that may be registered using the Option provided
 // by WithPreShutdownHook.
 type HookFunction func(ctx context.Context) error
@@ -96,6 +66,22 @@ func (f hookFunction) set(r *Runner) {
 
 // - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
 
+// StopTimeout returns an Option that limits each closer registered with
+// Runner.OnStop to the given Duration. Closers are always limited by what
+// remains of the lame-duck period; this Option may only shorten that time.
+// A zero value (the default) imposes no additional limit.
+func StopTimeout(d time.Duration) Option {
+	return stopTimeout(d)
+}
+
+type stopTimeout time.Duration
+
+func (d stopTimeout) set(r *Runner) {
+	r.stopTimeout = time.Duration(d)
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
 func ErrServerClosedOK() Option {
 	return new(escOK)
 }
@@ -107,3 +93,48 @@ func (e *escOK) set(r *Runner) {
 }
 
 // - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// DrainDelay returns an Option that delays the Server's Shutdown by the given
+// Duration once lame-duck mode is triggered. During this time, the Server
+// continues to serve normally (though the Runner's LameDuck channel has been
+// closed), allowing load balancers time to stop sending it new traffic. The
+// lame-duck Period begins once the drain delay has elapsed. The default is
+// zero.
+func DrainDelay(d time.Duration) Option {
+	return drainDelay(d)
+}
+
+type drainDelay time.Duration
+
+func (d drainDelay) set(r *Runner) {
+	r.drainDelay = time.Duration(d)
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// MinLameDuck returns an Option that keeps the Runner in lame-duck mode for
+// at least the given Duration after it is triggered, should the Server's
+// Shutdown method (and everything else lame-duck mode waits upon) complete
+// sooner. Until this time has elapsed, Run does not return and the Runner's
+// State remains Stopping. This keeps a quickly drained process (and, e.g.,
+// its health checks) around long enough for load balancers to notice it is
+// going away.
+//
+// Note that Shutdown is still called as usual, so a Server such as
+// http.Server stops accepting new connections when it is called; the minimum
+// merely delays Run's return. To keep serving new connections after lame-duck
+// mode is triggered, use DrainDelay.
+//
+// The minimum applies only to a successful shutdown; if the lame-duck period
+// expires or Shutdown fails, Run returns as usual. The default is zero.
+func MinLameDuck(d time.Duration) Option {
+	return minLameDuck(d)
+}
+
+type minLameDuck time.Duration
+
+func (d minLameDuck) set(r *Runner) {
+	r.minLameDuck = time.Duration(d)
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
@@ -0,0 +1,134 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrStartupTimeout is the error returned by Run (wrapped in a LameDuckError)
+// when a Server fails to report readiness within the time allowed by the
+// StartupTimeout Option.
+var ErrStartupTimeout = errors.New("server not ready before startup timeout")
+
+// ReadyFunc is called by a Server to report that it is ready to serve
+// requests; see ReadyReporter.
+type ReadyFunc func()
+
+// ReadyReporter is an optional interface that may be implemented by a Server
+// that reports its own readiness. Ordinarily, a Runner's Ready channel is
+// closed as its Server's Serve method is called; however, if the Server
+// implements ReadyReporter and its ReportsReady method returns true, the
+// Ready channel is instead closed once the Server calls the ReadyFunc
+// obtained from its Serve Context (see ReadyFromContext). This is typically
+// done once the Server's listener is bound and any warm-up is complete.
+//
+//     func (s *LameDuckServer) ReportsReady() bool { return true }
+//
+//     func (s *LameDuckServer) Serve(ctx context.Context) error {
+//       ln, err := net.Listen("tcp", s.Addr)
+//       if err != nil {
+//         return err
+//       }
+//
+//       lameduck.ReadyFromContext(ctx)()
+//
+//       if err := s.Server.Serve(ln); err != http.ErrServerClosed {
+//         return err
+//       }
+//
+//       return nil
+//     }
+//
+// See also the StartupTimeout Option.
+type ReadyReporter interface {
+	ReportsReady() bool
+}
+
+// reportsReady returns true if the receiver's Server reports its own
+// readiness.
+func (r *Runner) reportsReady() bool {
+	rr, ok := r.server.(ReadyReporter)
+	return ok && rr.ReportsReady()
+}
+
+// markReady closes the given Ready channel, if not already closed.
+func (r *Runner) markReady(ready chan struct{}) {
+	r.mu.Lock()
+
+	select {
+	case <-ready:
+		r.mu.Unlock()
+
+	default:
+		close(ready)
+		r.mu.Unlock()
+		r.logDebug("Server is ready")
+	}
+}
+
+// readyFunc returns a ReadyFunc closing the current run's Ready channel,
+// along with a function that disables it once Serve has returned.
+func (r *Runner) readyFunc() (ReadyFunc, func()) {
+	r.mu.Lock()
+	ready := r.ready
+	r.mu.Unlock()
+
+	var served int32
+
+	f := func() {
+		if atomic.LoadInt32(&served) == 0 {
+			r.markReady(ready)
+		}
+	}
+
+	return f, func() { atomic.StoreInt32(&served, 1) }
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// StartupTimeout returns an Option that limits the time allowed for a Server
+// to become ready (see ReadyReporter) once its Serve method is called. Should
+// this time elapse, the Server is closed and Run returns a LameDuckError with
+// its Failed field set to true and Err set to ErrStartupTimeout. A zero value
+// (the default) imposes no limit.
+//
+// Lame-duck mode beginning before the Server is ready is not considered a
+// startup failure.
+func StartupTimeout(d time.Duration) Option {
+	return startupTimeout(d)
+}
+
+type startupTimeout time.Duration
+
+func (d startupTimeout) set(r *Runner) {
+	r.startupTimeout = time.Duration(d)
+}
+
+// awaitReady waits for the receiver to become ready, for lame-duck mode to
+// begin or for ctx to be done. If the receiver's startup timeout elapses
+// first, its Server is closed and a LameDuckError is returned.
+func (r *Runner) awaitReady(ctx context.Context) error {
+	r.mu.Lock()
+	ready := r.ready
+	r.mu.Unlock()
+
+	timer := time.NewTimer(r.startupTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+	case <-r.lduck:
+	case <-ctx.Done():
+
+	case <-timer.C:
+		r.logError("Server not ready before startup timeout", "timeout", r.startupTimeout)
+		if err := r.server.Close(); err != nil {
+			r.logWarn("Error closing server", "error", err)
+		}
+		return &LameDuckError{Failed: true, Err: ErrStartupTimeout}
+	}
+
+	return nil
+}
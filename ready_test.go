@@ -0,0 +1,142 @@
+package lameduck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// readyServer is a testServer that reports its own readiness after delay.
+type readyServer struct {
+	*testServer
+	delay time.Duration
+}
+
+func (rs *readyServer) ReportsReady() bool { return true }
+
+func (rs *readyServer) Serve(ctx context.Context) error {
+	ready := ReadyFromContext(ctx)
+	t := time.AfterFunc(rs.delay, ready)
+	defer t.Stop()
+
+	return rs.testServer.Serve(ctx)
+}
+
+func TestReadyReporter(t *testing.T) {
+	tl := &testLogger{t.Logf}
+	svr := &readyServer{testServer: newTestServer(tl, nil, nil, nil), delay: 50 * time.Millisecond}
+	svr.shutdown.finish()
+
+	r, err := NewRunner(svr, WithLogger(tl), StartupTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	start := time.Now()
+
+	h, err := r.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start(ctx): %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Start returned after %v; wanted at least 50ms", elapsed)
+	}
+
+	if err := h.Stop(context.Background()); err != nil {
+		t.Errorf("h.Stop(ctx) == %v; wanted nil", err)
+	}
+}
+
+func TestStartupTimeout(t *testing.T) {
+	tl := &testLogger{t.Logf}
+	svr := &readyServer{testServer: newTestServer(tl, nil, nil, nil), delay: time.Minute}
+
+	r, err := NewRunner(svr, WithLogger(tl), StartupTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	_, err = r.Start(context.Background())
+
+	if want := (&LameDuckError{Failed: true, Err: ErrStartupTimeout}); !want.isEqual(err) {
+		t.Errorf("Start(ctx) == %#v; wanted %#v", err, want)
+	}
+
+	if got := r.LastReport().Outcome; got != ServeFailed {
+		t.Errorf("Report.Outcome == %v; wanted %v", got, ServeFailed)
+	}
+}
+
+func TestReadyFromContext(t *testing.T) {
+	// Must not panic without a Runner
+	ReadyFromContext(context.Background())()
+}
+
+// staleReadyServer reports readiness using the ReadyFunc from its first call
+// to Serve, then reports (on checked) whether calling that same ReadyFunc
+// from a later Serve closes the later run's Ready channel.
+type staleReadyServer struct {
+	*testServer
+	first   ReadyFunc
+	checked chan bool
+}
+
+func (rs *staleReadyServer) ReportsReady() bool { return true }
+
+func (rs *staleReadyServer) Serve(ctx context.Context) error {
+	if rs.first == nil {
+		rs.first = ReadyFromContext(ctx)
+		rs.first()
+	} else {
+		rs.first()
+
+		select {
+		case <-ctx.Value(runnerKey{}).(*Runner).Ready():
+			rs.checked <- true
+		default:
+			rs.checked <- false
+		}
+	}
+
+	return rs.testServer.Serve(ctx)
+}
+
+func TestReadyFuncStale(t *testing.T) {
+	ts := injectSignaller()
+	defer ts.revert()
+
+	tl := &testLogger{t.Logf}
+	svr := &staleReadyServer{testServer: newTestServer(tl, nil, nil, nil), checked: make(chan bool, 1)}
+	svr.shutdown.finish()
+
+	r, err := NewRunner(svr, WithLogger(tl), Period(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	h, err := r.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start(ctx): %v", err)
+	}
+
+	if err := h.Stop(context.Background()); err != nil {
+		t.Fatalf("h.Stop(ctx) == %v; wanted nil", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- r.Run(context.Background()) }()
+
+	if <-svr.checked {
+		t.Error("stale ReadyFunc closed the Ready channel of a later run")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	ts.emit(unix.SIGTERM)
+
+	if err := <-errs; err != nil {
+		t.Errorf("Run(ctx) == %v; wanted nil", err)
+	}
+}
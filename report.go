@@ -0,0 +1,71 @@
+package lameduck
+
+import "time"
+
+// Report summarizes a single call to Run.
+type Report struct {
+	Started   time.Time     // When Run was called
+	Triggered time.Time     // When lame-duck mode began; zero if it never did
+	Signal    string        // The signal that triggered lame-duck mode, if any
+	Deadline  time.Time     // The end of the lame-duck period; zero if it never began
+	Shutdown  time.Duration // Time taken by the Server's Shutdown method
+	Restarts  int           `json:",omitempty"` // Number of times Serve was restarted
+	Finished  time.Time     // When Run returned
+	Outcome   Outcome       // How Run concluded
+	Error     string        `json:",omitempty"` // The error returned by Run, if any
+
+	// Extensions lists any extensions granted to the lame-duck period; see
+	// the AdaptivePeriod Option.
+	Extensions []Extension `json:",omitempty"`
+}
+
+// LastReport returns a Report describing the receiver's most recently
+// completed call to Run, or nil if Run has not yet completed.
+func (r *Runner) LastReport() *Report {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lastReport == nil {
+		return nil
+	}
+
+	rp := *r.lastReport
+	return &rp
+}
+
+// report returns a copy of the Report for the current run.
+func (r *Runner) report() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.curReport
+}
+
+func (r *Runner) updateReport(f func(*Report)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f(&r.curReport)
+}
+
+// finishReport completes the Report for the current run, given the error
+// about to be returned by Run, and retains it as the receiver's LastReport.
+func (r *Runner) finishReport(err error) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rp := r.curReport
+	rp.Finished = time.Now()
+	rp.Outcome = OutcomeOf(err)
+	if err != nil {
+		rp.Error = err.Error()
+	}
+
+	r.lastReport = &rp
+
+	return rp
+}
@@ -0,0 +1,132 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+type restartOption struct {
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	jitter     float64
+}
+
+// RestartOnFailure returns an Option that restarts the Server (by calling its
+// Serve method again) should Serve return an error before lame-duck mode has
+// begun. Up to the given number of restarts are attempted over the course of
+// a call to Run; once these are exhausted, Run returns the last error from
+// Serve as it would without this Option.
+//
+// Before each restart, the Runner waits for an exponentially increasing
+// backoff: the first delay is backoff, and each subsequent delay is doubled up
+// to a limit of maxBackoff. Each delay is then randomly varied by up to the
+// given jitter (as a fraction of the delay; between 0 and 1).
+//
+// While waiting to restart, the Runner's State is Restarting; it returns to
+// Running when Serve is called again. If a lame-duck signal is received while
+// waiting, no restart takes place and lame-duck mode proceeds as usual.
+//
+// A run has a single Ready channel (see Runner.Ready) which, once closed,
+// remains closed across restarts; use State to distinguish a restarting
+// Server from a running one.
+func RestartOnFailure(attempts int, backoff, maxBackoff time.Duration, jitter float64) Option {
+	return &restartOption{attempts, backoff, maxBackoff, jitter}
+}
+
+func (o *restartOption) set(r *Runner) {
+	r.restart = o
+}
+
+func (o *restartOption) validate() error {
+	if o.attempts <= 0 {
+		return errors.New("restart attempts must be greater than zero")
+	}
+
+	if o.backoff <= 0 {
+		return errors.New("restart backoff must be greater than zero")
+	}
+
+	if o.maxBackoff < o.backoff {
+		return errors.New("maximum restart backoff must not be less than the initial backoff")
+	}
+
+	if o.jitter < 0 || o.jitter > 1 {
+		return errors.New("restart jitter must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// delay returns the backoff to be observed before the given restart attempt
+// (starting at 1).
+func (o *restartOption) delay(attempt int) time.Duration {
+	d := o.backoff
+	for i := 1; i < attempt && d < o.maxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > o.maxBackoff {
+		d = o.maxBackoff
+	}
+
+	return d + time.Duration((2*rand.Float64()-1)*o.jitter*float64(d))
+}
+
+// serveWithRestart calls the receiver's Server's Serve method, restarting it
+// as allowed by the RestartOnFailure Option should it fail. A nil error is
+// returned if Serve returns nil or if lame-duck mode begins while waiting to
+// restart; otherwise, the final error from Serve is returned.
+func (r *Runner) serveWithRestart(ctx context.Context) error {
+	for attempt := 1; ; attempt++ {
+		err := r.serve(ctx)
+		if err == nil || r.restart == nil {
+			return err
+		}
+
+		select {
+		case <-r.lduck:
+			// Failures during lame-duck mode are not retried
+			return err
+		default:
+		}
+
+		if attempt > r.restart.attempts {
+			r.logWarn("Restart attempts exhausted", "attempts", r.restart.attempts)
+			return err
+		}
+
+		delay := r.restart.delay(attempt)
+
+		r.setState(Restarting)
+		r.logWarn("Server failed; restarting after backoff", "error", err, "attempt", attempt, "backoff", delay)
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-r.lduck:
+			timer.Stop()
+			r.logInfo("Lame-duck mode began while awaiting restart")
+			return nil
+
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+
+		case <-timer.C:
+		}
+
+		select {
+		case <-r.lduck:
+			r.logInfo("Lame-duck mode began while awaiting restart")
+			return nil
+		default:
+		}
+
+		r.updateReport(func(rp *Report) { rp.Restarts++ })
+		r.logInfo("Restarting server", "attempt", attempt)
+		r.setState(Running)
+	}
+}
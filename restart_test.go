@@ -0,0 +1,131 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// flakyServer fails its first n calls to Serve, then behaves as a testServer.
+type flakyServer struct {
+	*testServer
+	err error
+
+	mu    sync.Mutex
+	fails int
+	calls int
+}
+
+func (fs *flakyServer) Serve(ctx context.Context) error {
+	fs.mu.Lock()
+	fs.calls++
+	fail := fs.calls <= fs.fails
+	fs.mu.Unlock()
+
+	if fail {
+		return fs.err
+	}
+
+	return fs.testServer.Serve(ctx)
+}
+
+func TestRestartOnFailure(t *testing.T) {
+	errServe := errors.New("cannot listen")
+
+	cases := map[string]struct {
+		fails    int
+		backoff  time.Duration
+		signal   bool
+		want     *LameDuckError
+		restarts int
+	}{
+		"recovered": {
+			fails:    2,
+			backoff:  time.Millisecond,
+			signal:   true,
+			restarts: 2,
+		},
+		"exhausted": {
+			fails:    10,
+			backoff:  time.Millisecond,
+			want:     &LameDuckError{Failed: true, Err: errServe},
+			restarts: 3,
+		},
+		"signal-during-backoff": {
+			fails:   10,
+			backoff: time.Minute,
+			signal:  true,
+		},
+	}
+
+	for label, tc := range cases {
+		tc := tc
+		t.Run(label, func(t *testing.T) {
+			ts := injectSignaller()
+			defer ts.revert()
+
+			tl := &testLogger{t.Logf}
+			svr := &flakyServer{testServer: newTestServer(tl, nil, nil, nil), err: errServe, fails: tc.fails}
+			svr.shutdown.finish()
+
+			r, err := NewRunner(svr, WithLogger(tl), Period(50*time.Millisecond), RestartOnFailure(3, tc.backoff, 4*tc.backoff, 0.5))
+			if err != nil {
+				t.Fatalf("cannot create Runner: %v", err)
+			}
+
+			errs := make(chan error, 1)
+			go func() { errs <- r.Run(context.Background()) }()
+
+			if tc.signal {
+				<-r.Ready()
+				time.Sleep(20 * time.Millisecond)
+				ts.emit(unix.SIGTERM)
+			}
+
+			if got := <-errs; !tc.want.isEqual(got) {
+				t.Errorf("Run(ctx) == %#v; wanted %#v", got, tc.want)
+			}
+
+			if got := r.LastReport().Restarts; got != tc.restarts {
+				t.Errorf("Report.Restarts == %d; wanted %d", got, tc.restarts)
+			}
+		})
+	}
+}
+
+func TestRestartDelay(t *testing.T) {
+	o := &restartOption{attempts: 10, backoff: time.Second, maxBackoff: 5 * time.Second}
+
+	for attempt, want := range []time.Duration{0, 1, 2, 4, 5, 5} {
+		if attempt == 0 {
+			continue
+		}
+		if got := o.delay(attempt); got != want*time.Second {
+			t.Errorf("delay(%d) == %v; wanted %v", attempt, got, want*time.Second)
+		}
+	}
+
+	o.jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := o.delay(1); d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Fatalf("delay(1) == %v; wanted within 50%% of %v", d, o.backoff)
+		}
+	}
+}
+
+func TestRestartOnFailureValidation(t *testing.T) {
+	for _, o := range []Option{
+		RestartOnFailure(0, time.Second, time.Second, 0),
+		RestartOnFailure(1, 0, time.Second, 0),
+		RestartOnFailure(1, time.Second, time.Millisecond, 0),
+		RestartOnFailure(1, time.Second, time.Second, 1.5),
+	} {
+		if _, err := NewRunner(newTestServer(nil, nil, nil, nil), o); err == nil {
+			t.Errorf("NewRunner(%+v) returned nil error", o)
+		}
+	}
+}
@@ -5,11 +5,11 @@ import (
 	"errors"
 	"net/http"
 	"os"
+	"runtime/trace"
 	"sync"
 	"time"
 
 	"golang.org/x/sys/unix"
-	"toolman.org/base/log/v2"
 )
 
 var (
@@ -17,20 +17,54 @@ var (
 	defaultSignals = []os.Signal{unix.SIGINT, unix.SIGTERM}
 )
 
+// ErrAlreadyRunning is returned by Runner.Run if it is called while a previous
+// call has yet to return.
+var ErrAlreadyRunning = errors.New("runner is already running")
+
 // Runner is the lame-duck coordinator for a type implementing the Server
 // interface.
 type Runner struct {
-	server  Server
-	period  time.Duration
-	escOK   bool
-	signals []os.Signal
-	logf    func(string, ...interface{})
-	psHook  hookFunction
-	state   State
-	ready   chan struct{}
-	done    chan struct{}
-
-	once sync.Once
+	server         Server
+	period         time.Duration
+	drainDelay     time.Duration
+	minLameDuck    time.Duration
+	escOK          bool
+	signals        []os.Signal
+	logger         StructuredLogger
+	psHook         hookFunction
+	conns          *ConnRegistry
+	shed           *shedOption
+	restart        *restartOption
+	stopTimeout    time.Duration
+	closers        []HookFunction
+	bgFuncs        []func(context.Context) error
+	metrics        MetricsSink
+	dump           *dumpOption
+	closeTimeout   time.Duration
+	closeExit      *int
+	exitCodes      ExitCodes
+	exitDelay      time.Duration
+	startupTimeout time.Duration
+	startGrace     time.Duration
+	cancelTrigger  bool
+	adaptive       *adaptiveOption
+	expvarName     string
+
+	// Per-run state; see beginRun and endRun
+	bg         *background
+	curReport  Report
+	lastReport *Report
+	state      State
+	running    bool
+	ran        bool // whether a previous run has completed
+	nclosers   int  // closers registered before the run began
+	ready      chan struct{}
+	lduck      chan struct{}
+	done       chan struct{}
+	trigger    chan struct{}
+	once       sync.Once
+
+	mu sync.Mutex
 }
 
 func newRunner(svr Server, options []Option) (*Runner, error) {
@@ -39,15 +73,18 @@ func newRunner(svr Server, options []Option) (*Runner, error) {
 	}
 
 	r := &Runner{
-		server:  svr,
-		period:  defaultPeriod,
-		signals: defaultSignals,
-		logf:    log.Infof,
-		state:   NotStarted,
-		ready:   make(chan struct{}),
-		done:    make(chan struct{}),
+		server:    svr,
+		period:    defaultPeriod,
+		signals:   defaultSignals,
+		logger:    StdLogger(nil),
+		conns:     newConnRegistry(),
+		metrics:   nopSink{},
+		exitCodes: DefaultExitCodes,
+		state:     NotStarted,
 	}
 
+	r.resetRun()
+
 	for _, o := range options {
 		o.set(r)
 	}
@@ -60,6 +97,56 @@ func newRunner(svr Server, options []Option) (*Runner, error) {
 		return nil, errors.New("no lame-duck signals defined")
 	}
 
+	if r.drainDelay < 0 {
+		return nil, errors.New("drain delay must not be negative")
+	}
+
+	if r.minLameDuck < 0 {
+		return nil, errors.New("minimum lame-duck duration must not be negative")
+	}
+
+	if r.closeTimeout < 0 {
+		return nil, errors.New("close timeout must not be negative")
+	}
+
+	if r.stopTimeout < 0 {
+		return nil, errors.New("stop timeout must not be negative")
+	}
+
+	if r.exitDelay < 0 {
+		return nil, errors.New("exit delay must not be negative")
+	}
+
+	if r.startupTimeout < 0 {
+		return nil, errors.New("startup timeout must not be negative")
+	}
+
+	if r.startGrace < 0 {
+		return nil, errors.New("start grace period must not be negative")
+	}
+
+	if r.shed != nil {
+		if err := r.shed.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.adaptive != nil {
+		if err := r.adaptive.validate(r.period); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.restart != nil {
+		if err := r.restart.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.publishExpvar(); err != nil {
+		return nil, err
+	}
+
 	return r, nil
 }
 
@@ -68,7 +155,13 @@ func (r *Runner) serve(ctx context.Context) error {
 		return errors.New("bad state: nil receiver")
 	}
 
+	ready, served := r.readyFunc()
+
+	ctx = context.WithValue(ctx, runnerKey{}, r)
+	ctx = context.WithValue(ctx, readyKey{}, ready)
+
 	err := r.server.Serve(ctx)
+	served()
 
 	switch {
 	case err == nil:
@@ -80,16 +173,141 @@ func (r *Runner) serve(ctx context.Context) error {
 	}
 }
 
+// beginRun marks the receiver as running, or returns ErrAlreadyRunning if it
+// already is. If a previous run has completed, fresh per-run channels are
+// created; until then, those of the previous run remain in place.
+func (r *Runner) beginRun() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return ErrAlreadyRunning
+	}
+
+	if r.ran {
+		r.resetRun()
+	}
+
+	r.nclosers = len(r.closers)
+	r.running = true
+	return nil
+}
+
+// endRun marks the receiver as no longer running and discards the closers
+// and connections registered during the run; closers registered beforehand
+// are kept for subsequent runs. The run's channels are left as they are (see
+// Ready and LameDuck) until the next call to beginRun.
+func (r *Runner) endRun() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closers = r.closers[:r.nclosers:r.nclosers]
+	r.conns.reset()
+	r.running = false
+	r.ran = true
+}
+
+// resetRun creates the channels and sync.Once used for a single run. It must
+// only be called while no run is in progress.
+func (r *Runner) resetRun() {
+	r.ready = make(chan struct{})
+	r.lduck = make(chan struct{})
+	r.done = make(chan struct{})
+	r.trigger = make(chan struct{})
+	r.once = sync.Once{}
+}
+
 // Ready returns a channel that is closed when the receiver's underlying
-// Server is ready to serve reqeuests.
+// Server is ready to serve reqeuests. Unless the Server implements
+// ReadyReporter, this is when its Serve method is called.
+//
+// Each call to Run has its own Ready channel. Once Run returns, this method
+// continues to return that run's channel until Run is called again.
 func (r *Runner) Ready() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	return r.ready
 }
 
+// Conns returns the receiver's ConnRegistry. Connections registered here
+// are coordinated with the receiver's lame-duck mode.
+func (r *Runner) Conns() *ConnRegistry {
+	if r == nil {
+		return nil
+	}
+	return r.conns
+}
+
+// forceClose calls Close on the receiver's Server and then closes all
+// connections remaining in its ConnRegistry. The first error encountered,
+// if any, is returned. The given Context is used only for tracing.
+//
+// See the CloseTimeout Option for limiting the time spent here.
+func (r *Runner) forceClose(ctx context.Context) error {
+	defer trace.StartRegion(ctx, "Close").End()
+
+	return r.closeWithTimeout(func() error {
+		err := r.server.Close()
+
+		if n := r.conns.Len(); n > 0 {
+			r.logInfo("Closing tracked connections", "count", n)
+			if cerr := r.conns.closeAll(); err == nil {
+				err = cerr
+			}
+		}
+
+		return err
+	})
+}
+
+// drain waits for the receiver's configured drain delay or until ctx is done,
+// whichever comes first.
+func (r *Runner) drain(ctx context.Context) {
+	timer := time.NewTimer(r.drainDelay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// holdLameDuck waits until the receiver's minimum lame-duck duration has
+// elapsed since triggered or until ctx is done, whichever comes first.
+func (r *Runner) holdLameDuck(ctx context.Context, triggered time.Time) {
+	wait := time.Until(triggered.Add(r.minLameDuck))
+	if wait <= 0 {
+		return
+	}
+
+	r.logInfo("Holding lame-duck mode for minimum duration", "minimum", r.minLameDuck, "remaining", wait)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// LameDuck returns a channel that is closed when the receiver enters lame-duck
+// mode; i.e. after one of the configured signals has been received but before
+// its Server's Shutdown method is called.
+//
+// As with Ready, each call to Run has its own LameDuck channel.
+func (r *Runner) LameDuck() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lduck
+}
+
 func (r *Runner) close() {
 	if r == nil || r.done == nil {
 		if r != nil {
-			r.logf("r.done is nil !!!")
+			r.logError("bad state: nil done channel")
 		}
 		return
 	}
@@ -98,11 +316,11 @@ func (r *Runner) close() {
 
 	r.once.Do(func() {
 		close(r.done)
-		r.logf("runner closed")
+		r.logDebug("Runner closed")
 		closed = true
 	})
 
 	if !closed {
-		r.logf("runner *NOT* closed")
+		r.logDebug("Runner already closed")
 	}
 }
@@ -38,8 +38,11 @@ package lameduck
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime/trace"
 	"strings"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -73,9 +76,10 @@ type Server interface {
 	// with its Expired field set to false.
 	Shutdown(context.Context) error
 
-	// Close is called by Run when Shutdown returns context.DeadlineExceeded and
-	// its return value will be assigned to the Err field of the LameDuckError
-	// returned by Run.
+	// Close is called by Run when Shutdown returns context.DeadlineExceeded (or
+	// the lame-duck period expires while waiting for connections tracked by the
+	// Runner's ConnRegistry) and its return value will be assigned to the Err
+	// field of the LameDuckError returned by Run.
 	Close() error
 }
 
@@ -114,12 +118,47 @@ func NewRunner(svr Server, options ...Option) (*Runner, error) {
 // Run executes the receiver's Server while providing coordinated lame-duck
 // behavior on receipt of one or more configurable signals.
 //
+// Each call to Run is recorded as a runtime/trace task ("lameduck.Run") with
+// regions for waiting on signals, the pre-shutdown hook, Shutdown and Close,
+// and log annotations for the triggering signal and the outcome.
+//
+// A Runner may be run any number of times, but only one call to Run may be in
+// progress at a time; Run returns ErrAlreadyRunning otherwise.
+//
 // See the Run func for details.
 func (r *Runner) Run(ctx context.Context) error {
+	if err := r.beginRun(); err != nil {
+		return err
+	}
+
+	return r.run(ctx)
+}
+
+// run is the implementation of Run; it must be preceded by a successful call
+// to beginRun.
+func (r *Runner) run(ctx context.Context) error {
+	defer r.endRun()
+
+	ctx, task := trace.NewTask(ctx, "lameduck.Run")
+	defer task.End()
+
+	parent := ctx
+	var parentDone <-chan struct{}
+
+	if r.cancelTrigger {
+		parentDone = parent.Done()
+		ctx = detachedContext{parent}
+	}
+
 	eg, ctx := errgroup.WithContext(ctx)
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	r.updateReport(func(rp *Report) { *rp = Report{Started: time.Now()} })
+
+	r.startBackground(ctx, eg)
+	defer r.endBackground()
+
 	// Goroutine #1
 	//
 	//   - Waits for one of the configured signals
@@ -131,78 +170,181 @@ func (r *Runner) Run(ctx context.Context) error {
 	eg.Go(func() error {
 		defer r.close()
 
-		r.logf("Waiting for signals: %v", r.signals)
+		r.logInfo("Waiting for signals", "signals", r.signals)
+
+		region := trace.StartRegion(ctx, "waitForSignal")
+		sig, err := r.waitForSignal(ctx, parentDone)
+		region.End()
 
-		sig, err := r.waitForSignal(ctx)
 		if err != nil {
 			return &LameDuckError{Err: err}
 		}
 
-		r.logf("Received signal [%s]; entering lame-duck mode for %v", sig, r.period)
+		canceled := sig == cancelTrigger
+
+		if canceled {
+			r.logInfo("Context canceled; entering lame-duck mode", "error", parent.Err(), "period", r.period)
+		} else {
+			r.logInfo("Received signal; entering lame-duck mode", "signal", sig, "period", r.period)
+		}
+		trace.Log(ctx, "signal", sig.String())
+
+		now := time.Now()
+		deadline := now.Add(r.drainDelay + r.period)
+
+		r.updateReport(func(rp *Report) {
+			rp.Triggered = now
+			rp.Signal = sig.String()
+			rp.Deadline = deadline
+		})
 
-		ctx, cancel2 := context.WithTimeout(ctx, r.period)
+		close(r.lduck)
+		r.stopBackground()
+
+		runCtx := ctx
+		ctx := context.WithValue(ctx, signalKey{}, sig)
+
+		var cancel2 context.CancelFunc
+		if r.adaptive != nil {
+			limit := now.Add(r.drainDelay + r.adaptive.max)
+			ctx, cancel2 = newExtendableContext(ctx, deadline, r.extender(limit))
+		} else {
+			ctx, cancel2 = context.WithDeadline(ctx, deadline)
+		}
 		defer cancel2()
 
+		if r.drainDelay > 0 {
+			r.logInfo("Draining before shutdown", "delay", r.drainDelay)
+			region := trace.StartRegion(ctx, "drainDelay")
+			r.drain(ctx)
+			region.End()
+		}
+
+		if r.shed != nil {
+			r.shedConns(ctx)
+		} else {
+			r.conns.notifyAll()
+		}
+
 		if r.psHook != nil {
-			r.logf("Calling configured pre-shutdown hook")
+			r.logInfo("Calling configured pre-shutdown hook")
+			start := time.Now()
+			region := trace.StartRegion(ctx, "preShutdownHook")
 			if err := r.psHook(ctx); err != nil {
-				r.logf("Pre-shutdown hook failed: %v", err)
+				r.logWarn("Pre-shutdown hook failed", "error", err)
 			}
+			region.End()
+			r.metrics.ObserveHook("pre_shutdown", time.Since(start))
 		}
 
+		start := time.Now()
+		region = trace.StartRegion(ctx, "Shutdown")
 		err = r.server.Shutdown(ctx)
+		region.End()
+		elapsed := time.Since(start)
+		r.metrics.ObserveShutdown(elapsed)
+		r.updateReport(func(rp *Report) { rp.Shutdown = elapsed })
+
+		if err == nil {
+			if n := r.conns.Len(); n > 0 {
+				r.logInfo("Waiting for tracked connections", "count", n)
+				err = r.conns.wait(ctx)
+			}
+		}
+
+		if err == nil {
+			err = r.waitBackground(ctx)
+		}
+
 		switch err {
 		case nil:
-			r.logf("Completed lame-duck mode")
+			if r.minLameDuck > 0 {
+				region := trace.StartRegion(ctx, "minLameDuck")
+				r.holdLameDuck(runCtx, now)
+				region.End()
+			}
+
+			r.logInfo("Completed lame-duck mode", "shutdown", elapsed)
+			if canceled {
+				return &LameDuckError{Canceled: true}
+			}
 			return nil
 
 		case context.DeadlineExceeded:
-			r.logf("Lame-duck period has expired")
-			return &LameDuckError{Expired: true, Err: r.server.Close()}
+			r.logWarn("Lame-duck period has expired", "period", r.period)
+			r.dumpGoroutines()
+			err := r.forceClose(ctx)
+			return &LameDuckError{Expired: true, Canceled: canceled, CloseTimedOut: err == ErrCloseTimeout, Err: err}
 
 		default:
-			r.logf("error shutting down server: %v", err)
+			r.logError("Error shutting down server", "error", err)
 			cancel()
-			return &LameDuckError{Err: err}
+			return &LameDuckError{Canceled: canceled, Err: err}
 		}
 	})
 
+	if r.startupTimeout > 0 {
+		eg.Go(func() error { return r.awaitReady(ctx) })
+	}
+
 	// Goroutine #2
 	//
 	//   - Calls Serve
-	//   - If Server returns a non-nil error, return it immediately
+	//   - If Server returns a non-nil error, restart it if so configured
+	//     or return it immediately
 	//   - Otherwise, wait for the Context or receiver to be "done"
 	//     and return nil.
 	//
 	eg.Go(func() error {
 		defer r.close()
 
-		r.logf("Starting server")
-		r.state = Running
-		close(r.ready)
+		r.logInfo("Starting server")
+		r.setState(Running)
+		if !r.reportsReady() {
+			r.markReady(r.ready)
+		}
 
-		if err := r.serve(ctx); err != nil {
-			r.state = Failed
-			r.logf("Server failed: %v", err)
+		if err := r.serveWithRestart(ctx); err != nil {
+			r.setState(Failed)
+			r.logError("Server failed", "error", err)
 			return &LameDuckError{Failed: true, Err: err}
 		}
 
-		r.state = Stopping
-		r.logf("Stopping server")
+		r.setState(Stopping)
+		r.logInfo("Stopping server")
 
 		select {
 		case <-ctx.Done():
-			r.logf("Context canceled waiting for server shutdown")
+			r.logWarn("Context canceled waiting for server shutdown", "error", ctx.Err())
 
 		case <-r.done:
-			r.logf("Server stopped")
+			r.logInfo("Server stopped")
 		}
 
-		r.state = Stopped
+		r.setState(Stopped)
 		return nil
 	})
 
-	return eg.Wait()
+	err := eg.Wait()
+
+	if errs := r.runClosers(); len(errs) != 0 {
+		lde, ok := err.(*LameDuckError)
+		if !ok || lde == nil {
+			lde = &LameDuckError{Err: err}
+		}
+		lde.StopErrors = errs
+		err = lde
+	}
+
+	rp := r.finishReport(err)
+	trace.Log(ctx, "outcome", rp.Outcome.String())
+
+	r.metrics.ObserveRun(rp.Outcome)
+	if !rp.Triggered.IsZero() {
+		r.metrics.ObserveTriggerToExit(rp.Finished.Sub(rp.Triggered))
+	}
+
+	return err
 }
 
 // LameDuckError is the error type returned by Run for errors related to
@@ -211,6 +353,18 @@ type LameDuckError struct {
 	Expired bool
 	Failed  bool
 	Err     error
+
+	// Canceled is true if lame-duck mode was triggered by cancellation of the
+	// Context passed to Run; see the ShutdownOnCancel Option.
+	Canceled bool
+
+	// CloseTimedOut is true if the Server's Close method failed to return
+	// within the time allowed by the CloseTimeout Option.
+	CloseTimedOut bool
+
+	// StopErrors holds any errors returned by closers registered using
+	// Runner.OnStop.
+	StopErrors []error
 }
 
 func (lde *LameDuckError) Error() string {
@@ -224,12 +378,20 @@ func (lde *LameDuckError) Error() string {
 		msgs = append(msgs, "Lame-duck period has expired")
 	}
 
+	if lde.Canceled {
+		msgs = append(msgs, "Lame-duck mode triggered by Context cancellation")
+	}
+
 	if lde.Err != nil {
 		if msg := lde.Err.Error(); msg != "" {
 			msgs = append(msgs, msg)
 		}
 	}
 
+	for _, err := range lde.StopErrors {
+		msgs = append(msgs, "stop: "+err.Error())
+	}
+
 	if len(msgs) == 0 {
 		return ""
 	}
@@ -244,6 +406,38 @@ func (lde *LameDuckError) Unwrap() error {
 	return lde.Err
 }
 
+// Is reports whether any of the receiver's StopErrors matches target, which
+// allows errors.Is to see them in addition to Err (see Unwrap).
+func (lde *LameDuckError) Is(target error) bool {
+	if lde == nil {
+		return false
+	}
+
+	for _, err := range lde.StopErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first of the receiver's StopErrors that matches target, which
+// allows errors.As to see them in addition to Err (see Unwrap).
+func (lde *LameDuckError) As(target interface{}) bool {
+	if lde == nil {
+		return false
+	}
+
+	for _, err := range lde.StopErrors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (lde *LameDuckError) GoString() string {
 	if lde == nil {
 		return "<nil>"
@@ -259,6 +453,14 @@ func (lde *LameDuckError) GoString() string {
 		parts = append(parts, fmt.Sprint("Failed: true"))
 	}
 
+	if lde.Canceled {
+		parts = append(parts, fmt.Sprint("Canceled: true"))
+	}
+
+	if lde.CloseTimedOut {
+		parts = append(parts, fmt.Sprint("CloseTimedOut: true"))
+	}
+
 	switch lde.Err {
 	case nil:
 		// nop
@@ -268,5 +470,9 @@ func (lde *LameDuckError) GoString() string {
 		parts = append(parts, fmt.Sprintf("Err: %T{%v}", lde.Err, lde.Err))
 	}
 
+	if len(lde.StopErrors) != 0 {
+		parts = append(parts, fmt.Sprintf("StopErrors: %v", lde.StopErrors))
+	}
+
 	return fmt.Sprintf("&LameDuckError{%s}", strings.Join(parts, ", "))
 }
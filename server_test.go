@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"strings"
 	"sync"
@@ -228,3 +230,145 @@ func (lde *LameDuckError) isEqual(err error) bool {
 
 	return false
 }
+
+func TestDrainDelay(t *testing.T) {
+	ts := injectSignaller()
+	defer ts.revert()
+
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, nil, nil, nil)
+	svr.shutdown.finish()
+
+	r, err := NewRunner(svr, WithLogger(tl), DrainDelay(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- r.Run(context.Background()) }()
+
+	<-r.Ready()
+	time.Sleep(10 * time.Millisecond)
+	ts.emit(unix.SIGTERM)
+
+	<-r.LameDuck()
+	start := time.Now()
+
+	if err := <-errs; err != nil {
+		t.Fatalf("Run(ctx) == %v; wanted nil", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Run returned %v after lame-duck began; wanted at least the 50ms drain delay", elapsed)
+	}
+}
+
+func TestRunTwice(t *testing.T) {
+	ts := injectSignaller()
+	defer ts.revert()
+
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, nil, nil, nil)
+	svr.shutdown.finish()
+
+	r, err := NewRunner(svr, WithLogger(tl), Period(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	var closed, perRun int
+	r.OnStopFunc(func(context.Context) error { closed++; return nil })
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	var prev <-chan struct{}
+
+	for i := 1; i <= 2; i++ {
+		errs := make(chan error, 1)
+		go func() { errs <- r.Run(context.Background()) }()
+
+		// The previous run's (closed) channels remain until Run starts again.
+		for r.Ready() == prev {
+			time.Sleep(time.Millisecond)
+		}
+		prev = r.Ready()
+
+		<-prev
+		r.Conns().ConnState(c1, http.StateIdle)
+		r.OnStopFunc(func(context.Context) error { perRun++; return nil })
+
+		if err := r.Run(context.Background()); err != ErrAlreadyRunning {
+			t.Errorf("run %d: concurrent Run(ctx) == %v; wanted %v", i, err, ErrAlreadyRunning)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		ts.emit(unix.SIGTERM)
+
+		if err := <-errs; err != nil {
+			t.Fatalf("run %d: Run(ctx) == %v; wanted nil", i, err)
+		}
+
+		if got := r.State(); got != Stopped {
+			t.Errorf("run %d: State() == %v; wanted %v", i, got, Stopped)
+		}
+
+		for label, ch := range map[string]<-chan struct{}{"Ready": r.Ready(), "LameDuck": r.LameDuck()} {
+			select {
+			case <-ch:
+			default:
+				t.Errorf("run %d: %s channel not closed after Run", i, label)
+			}
+		}
+
+		if n := r.Conns().keepAliveLen(); n != 0 {
+			t.Errorf("run %d: %d keep-alive connections remain after Run", i, n)
+		}
+	}
+
+	if closed != 2 {
+		t.Errorf("closer registered before Run called %d times; wanted 2", closed)
+	}
+
+	if perRun != 2 {
+		t.Errorf("closers registered during Run called %d times in total; wanted 2 (once each)", perRun)
+	}
+}
+
+func TestMinLameDuck(t *testing.T) {
+	ts := injectSignaller()
+	defer ts.revert()
+
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, nil, nil, nil)
+	svr.shutdown.finish()
+
+	r, err := NewRunner(svr, WithLogger(tl), MinLameDuck(80*time.Millisecond))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- r.Run(context.Background()) }()
+
+	<-r.Ready()
+	time.Sleep(10 * time.Millisecond)
+	ts.emit(unix.SIGTERM)
+
+	<-r.LameDuck()
+	start := time.Now()
+
+	time.Sleep(40 * time.Millisecond)
+	if got := r.State(); got != Stopping {
+		t.Errorf("State() == %v during minimum lame-duck; wanted %v", got, Stopping)
+	}
+
+	if err := <-errs; err != nil {
+		t.Fatalf("Run(ctx) == %v; wanted nil", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("Run returned %v after lame-duck began; wanted at least the 80ms minimum", elapsed)
+	}
+}
@@ -0,0 +1,163 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+)
+
+type shedOption struct {
+	fraction float64
+	jitter   float64
+}
+
+// ShedConnections returns an Option that sheds connections gradually, rather
+// than all at once, when lame-duck mode begins. This helps prevent a draining
+// server's clients from reconnecting elsewhere all at the same moment.
+//
+// Shedding takes place across the given fraction (which must be greater than
+// zero and less than 1) of whatever remains of the lame-duck Period when
+// shedding begins, before the Server's Shutdown method is called; the rest is
+// left for Shutdown. During this time, each connection tracked by the Runner's
+// ConnRegistry is handled in turn, at evenly spaced intervals: registered
+// connections have their notify functions called and keep-alive connections
+// (see ConnRegistry.ConnState) are closed once idle. A keep-alive connection
+// that is still active when its turn comes is closed as soon as it is seen to
+// be idle at a later interval; any still active at the end are left to the
+// Server's Shutdown method.
+//
+// Each interval is offset by a random amount up to the given jitter (as a
+// fraction of the interval; between 0 and 1) to further spread out
+// reconnects. The number of shed connections is logged and reported to the
+// Runner's MetricsSink.
+func ShedConnections(fraction, jitter float64) Option {
+	return &shedOption{fraction, jitter}
+}
+
+func (o *shedOption) set(r *Runner) {
+	r.shed = o
+}
+
+func (o *shedOption) validate() error {
+	if o.fraction <= 0 || o.fraction >= 1 {
+		return errors.New("connection shedding fraction must be greater than zero and less than 1")
+	}
+
+	if o.jitter < 0 || o.jitter > 1 {
+		return errors.New("connection shedding jitter must be between 0 and 1")
+	}
+
+	return nil
+}
+
+type shedTarget struct {
+	tc   *trackedConn
+	conn net.Conn
+}
+
+// shedConns is called in lieu of r.conns.notifyAll when the ShedConnections
+// Option is in effect. It returns once all connections have been shed, the
+// shedding window has elapsed or ctx is done, whichever comes first.
+func (r *Runner) shedConns(ctx context.Context) {
+	var targets []shedTarget
+
+	for _, tc := range r.conns.beginLameDuck() {
+		targets = append(targets, shedTarget{tc: tc})
+	}
+
+	for _, c := range r.conns.keepAliveConns() {
+		targets = append(targets, shedTarget{conn: c})
+	}
+
+	if len(targets) == 0 {
+		return
+	}
+
+	rand.Shuffle(len(targets), func(i, j int) {
+		targets[i], targets[j] = targets[j], targets[i]
+	})
+
+	remaining := r.period
+	if dl, ok := ctx.Deadline(); ok {
+		remaining = time.Until(dl)
+	}
+
+	window := time.Duration(float64(remaining) * r.shed.fraction)
+	slot := window / time.Duration(len(targets))
+	start := time.Now()
+
+	r.logInfo("Shedding connections", "count", len(targets), "window", window)
+
+	var (
+		notified, closed int
+		pending          []net.Conn // keep-alive connections that were active
+	)
+
+	// closePending closes those pending connections that are now idle.
+	closePending := func() {
+		remaining := pending[:0]
+		for _, c := range pending {
+			if r.conns.closeIfIdle(c) {
+				closed++
+			} else if r.conns.isKeepAlive(c) {
+				remaining = append(remaining, c)
+			}
+		}
+		pending = remaining
+	}
+
+	defer func() {
+		r.metrics.ObserveShed(notified, closed)
+	}()
+
+	for i, t := range targets {
+		at := time.Duration(i)*slot + time.Duration(rand.Float64()*r.shed.jitter*float64(slot))
+
+		timer := time.NewTimer(time.Until(start.Add(at)))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			// Don't leave anyone uninformed
+			for _, t := range targets[i:] {
+				if t.tc != nil {
+					t.tc.notifyAsync()
+					notified++
+				}
+			}
+			r.logWarn("Connection shedding interrupted", "error", ctx.Err(), "notified", notified, "closed", closed)
+			return
+
+		case <-timer.C:
+		}
+
+		closePending()
+
+		switch {
+		case t.tc != nil:
+			t.tc.notifyAsync()
+			notified++
+
+		case r.conns.closeIfIdle(t.conn):
+			closed++
+
+		case r.conns.isKeepAlive(t.conn):
+			pending = append(pending, t.conn)
+		}
+	}
+
+	// Give still-active connections until the end of the window to go idle.
+	if len(pending) != 0 {
+		timer := time.NewTimer(time.Until(start.Add(window)))
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+		closePending()
+	}
+
+	r.logInfo("Shed connections", "notified", notified, "closed", closed, "active", len(pending))
+}
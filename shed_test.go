@@ -0,0 +1,130 @@
+package lameduck
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShedConns(t *testing.T) {
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, nil, nil, nil)
+
+	m := NewMetrics()
+
+	r, err := NewRunner(svr, WithLogger(tl), WithMetrics(m), Period(100*time.Millisecond), ShedConnections(0.5, 0.5))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	var idle, active []net.Conn
+
+	for i := 0; i < 3; i++ {
+		c1, c2 := net.Pipe()
+		defer c2.Close()
+		r.Conns().ConnState(c1, http.StateIdle)
+		idle = append(idle, c2)
+	}
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	r.Conns().ConnState(c1, http.StateActive)
+	active = append(active, c2)
+
+	// Active when shedding begins but idle shortly thereafter
+	c1, c3 := net.Pipe()
+	defer c3.Close()
+	r.Conns().ConnState(c1, http.StateActive)
+	idle = append(idle, c3)
+	time.AfterFunc(10*time.Millisecond, func() { r.Conns().ConnState(c1, http.StateIdle) })
+
+	notified := make(chan struct{}, 3)
+	for i := 0; i < 3; i++ {
+		r.Conns().Register(newTestConn(), func() { notified <- struct{}{} })
+	}
+
+	start := time.Now()
+	r.shedConns(context.Background())
+
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond || elapsed > 100*time.Millisecond {
+		t.Errorf("shedding took %v; wanted something near 50ms", elapsed)
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-notified:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d of 3 tracked connections notified", i)
+		}
+	}
+
+	buf := make([]byte, 1)
+
+	for _, c := range idle {
+		if _, err := c.Read(buf); err == nil {
+			t.Error("idle connection not closed")
+		}
+	}
+
+	for _, c := range active {
+		c.SetReadDeadline(time.Now().Add(time.Millisecond))
+		if _, err := c.Read(buf); err == nil || !isTimeout(err) {
+			t.Errorf("active connection read error == %v; wanted timeout", err)
+		}
+	}
+
+	var out strings.Builder
+	m.WriteTo(&out)
+
+	for _, want := range []string{
+		`lameduck_shed_connections_total{action="closed"} 4`,
+		`lameduck_shed_connections_total{action="notified"} 3`,
+	} {
+		if !strings.Contains(out.String(), want+"\n") {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
+
+func TestShedConnectionsValidation(t *testing.T) {
+	svr := newTestServer(nil, nil, nil, nil)
+
+	for _, o := range []Option{ShedConnections(0, 0), ShedConnections(1, 0), ShedConnections(1.5, 0), ShedConnections(0.5, -1)} {
+		if _, err := NewRunner(svr, o); err == nil {
+			t.Errorf("NewRunner(svr, %#v) returned nil error", o)
+		}
+	}
+}
+
+func TestShedWindowRemaining(t *testing.T) {
+	tl := &testLogger{t.Logf}
+
+	r, err := NewRunner(newTestServer(tl, nil, nil, nil), WithLogger(tl), Period(time.Second), ShedConnections(0.5, 0))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		r.Conns().Register(newTestConn(), nil)
+	}
+
+	// The window is based on the time left, not the full Period.
+	ctx, cancel := context.WithTimeout(context.Background(), 40*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	r.shedConns(ctx)
+
+	if elapsed := time.Since(start); elapsed > 35*time.Millisecond {
+		t.Errorf("shedding took %v; wanted something near 20ms", elapsed)
+	}
+}
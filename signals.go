@@ -2,8 +2,14 @@ package lameduck
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
 )
 
 type osSignals struct{}
@@ -18,7 +24,9 @@ type signaler interface {
 	stop(chan<- os.Signal)
 }
 
-func (r *Runner) waitForSignal(ctx context.Context) (os.Signal, error) {
+// waitForSignal waits for one of the receiver's configured signals, a call to
+// Handle.Stop or, if cancel is non-nil, for cancel to be closed.
+func (r *Runner) waitForSignal(ctx context.Context, cancel <-chan struct{}) (os.Signal, error) {
 	ch := make(chan os.Signal, 1)
 	defer close(ch)
 
@@ -31,5 +39,102 @@ func (r *Runner) waitForSignal(ctx context.Context) (os.Signal, error) {
 
 	case sig := <-ch:
 		return sig, nil
+
+	case <-r.trigger:
+		return stopTrigger, nil
+
+	case <-cancel:
+		return cancelTrigger, nil
 	}
 }
+
+// manualTrigger is the os.Signal reported (e.g. by TriggerSignal) when
+// lame-duck mode is triggered by something other than an actual signal.
+type manualTrigger string
+
+func (t manualTrigger) Signal()        {}
+func (t manualTrigger) String() string { return string(t) }
+
+// stopTrigger is reported when lame-duck mode is triggered by Handle.Stop.
+const stopTrigger = manualTrigger("stop")
+
+// ParseSignal returns the signal specified by name, with or without its "SIG"
+// prefix, or by number; e.g. "SIGTERM", "TERM" (case-insensitive) or "15".
+// Names are those known to golang.org/x/sys/unix.
+func ParseSignal(s string) (syscall.Signal, error) {
+	s = strings.TrimSpace(s)
+
+	if n, err := strconv.Atoi(s); err == nil {
+		if unix.SignalName(syscall.Signal(n)) == "" {
+			return 0, fmt.Errorf("unknown signal number %d", n)
+		}
+		return syscall.Signal(n), nil
+	}
+
+	name := strings.ToUpper(s)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+
+	if sig := unix.SignalNum(name); sig != 0 {
+		return sig, nil
+	}
+
+	return 0, fmt.Errorf("unknown signal %q", s)
+}
+
+// signalName returns the short name (e.g. "TERM") for sig.
+func signalName(sig os.Signal) string {
+	if s, ok := sig.(syscall.Signal); ok {
+		if name := unix.SignalName(s); name != "" {
+			return strings.TrimPrefix(name, "SIG")
+		}
+	}
+	return sig.String()
+}
+
+// - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -
+
+// SignalList is a list of signals that implements both flag.Value and
+// pflag.Value. Its string form is a comma separated list of signals, each
+// as accepted by ParseSignal.
+//
+//     var sigs lameduck.SignalList
+//     flag.Var(&sigs, "signals", "comma separated list of signals")
+//     ...
+//     lameduck.Run(ctx, svr, lameduck.Signals(sigs...))
+//
+type SignalList []os.Signal
+
+// Type implements pflag.Value.
+func (sl *SignalList) Type() string { return "signals" }
+
+func (sl *SignalList) String() string {
+	if sl == nil {
+		return ""
+	}
+
+	names := make([]string, len(*sl))
+	for i, s := range *sl {
+		names[i] = signalName(s)
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Set replaces the receiver's contents with the comma separated list of
+// signals in s.
+func (sl *SignalList) Set(s string) error {
+	var sigs []os.Signal
+
+	for _, name := range strings.Split(s, ",") {
+		sig, err := ParseSignal(name)
+		if err != nil {
+			return err
+		}
+		sigs = append(sigs, sig)
+	}
+
+	*sl = sigs
+	return nil
+}
@@ -0,0 +1,42 @@
+package lameduck
+
+import (
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	for _, s := range []string{"TERM", "term", "SIGTERM", " 15 "} {
+		if got, err := ParseSignal(s); err != nil || got != syscall.SIGTERM {
+			t.Errorf("ParseSignal(%q) == (%v, %v); wanted (%v, nil)", s, got, err, syscall.SIGTERM)
+		}
+	}
+
+	for _, s := range []string{"", "NOPE", "SIG", "999"} {
+		if _, err := ParseSignal(s); err == nil {
+			t.Errorf("ParseSignal(%q) returned nil error", s)
+		}
+	}
+}
+
+func TestSignalList(t *testing.T) {
+	var sl SignalList
+
+	if err := sl.Set("INT,SIGHUP,15"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	if want := []os.Signal{syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM}; !reflect.DeepEqual([]os.Signal(sl), want) {
+		t.Errorf("Set: got %v; wanted %v", sl, want)
+	}
+
+	if got, want := sl.String(), "INT,HUP,TERM"; got != want {
+		t.Errorf("String() == %q; wanted %q", got, want)
+	}
+
+	if err := sl.Set("INT,NOPE"); err == nil {
+		t.Errorf("Set(%q) returned nil error", "INT,NOPE")
+	}
+}
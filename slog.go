@@ -0,0 +1,41 @@
+//go:build go1.21
+// +build go1.21
+
+package lameduck
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLogger returns a StructuredLogger that writes to l (or, if l is nil, to
+// slog.Default()). Levels are mapped to their log/slog equivalents.
+func SlogLogger(l *slog.Logger) StructuredLogger {
+	return &slogLogger{l}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (sl *slogLogger) Log(level Level, msg string, keyvals ...interface{}) {
+	l := sl.logger
+	if l == nil {
+		l = slog.Default()
+	}
+
+	l.Log(context.Background(), slogLevel(level), msg, keyvals...)
+}
+
+func slogLevel(l Level) slog.Level {
+	switch {
+	case l <= LevelDebug:
+		return slog.LevelDebug
+	case l == LevelInfo:
+		return slog.LevelInfo
+	case l == LevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
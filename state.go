@@ -1,5 +1,10 @@
 package lameduck
 
+import (
+	"fmt"
+	"strings"
+)
+
 // State represents the lame-duck runtime state for a Server.
 type State int
 
@@ -10,8 +15,11 @@ const (
 	Failed                  // The Server failed to start
 	Stopping                // The Server is in the process of stopping
 	Stopped                 // The Server has been stopped.
+	Restarting              // The Server failed and is waiting to be restarted
 )
 
+var states = []State{Unknown, NotStarted, Running, Failed, Stopping, Stopped, Restarting}
+
 func (s State) String() string {
 	switch s {
 	case NotStarted:
@@ -24,16 +32,61 @@ func (s State) String() string {
 		return "STOPPED"
 	case Stopping:
 		return "STOPPING"
+	case Restarting:
+		return "RESTARTING"
 	default:
 		return "UNKNOWN"
 	}
 }
 
+// ParseState returns the State named by s, as returned by its String method.
+// Matching is case-insensitive.
+func ParseState(s string) (State, error) {
+	for _, st := range states {
+		if strings.EqualFold(st.String(), s) {
+			return st, nil
+		}
+	}
+
+	return Unknown, fmt.Errorf("unknown state %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *State) UnmarshalText(text []byte) error {
+	st, err := ParseState(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = st
+	return nil
+}
+
 // State returns the current runtime State for the receiver.
 func (r *Runner) State() State {
-	if r == nil || r.done == nil {
+	if r == nil {
+		return Unknown
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done == nil {
 		return Unknown
 	}
 
 	return r.state
 }
+
+func (r *Runner) setState(s State) {
+	r.mu.Lock()
+	r.state = s
+	r.mu.Unlock()
+
+	r.metrics.SetState(s)
+}
@@ -0,0 +1,28 @@
+package lameduck
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStateText(t *testing.T) {
+	for _, s := range states {
+		b, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("json.Marshal(%v): %v", s, err)
+		}
+
+		var got State
+		if err := json.Unmarshal(b, &got); err != nil || got != s {
+			t.Errorf("json.Unmarshal(%s) == (%v, %v); wanted (%v, nil)", b, got, err, s)
+		}
+	}
+
+	if got, err := ParseState("stopping"); err != nil || got != Stopping {
+		t.Errorf("ParseState(%q) == (%v, %v); wanted (%v, nil)", "stopping", got, err, Stopping)
+	}
+
+	if _, err := ParseState("bogus"); err == nil {
+		t.Errorf("ParseState(%q) returned nil error", "bogus")
+	}
+}
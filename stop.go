@@ -0,0 +1,97 @@
+package lameduck
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// OnStop registers c to be closed after the receiver's Server has stopped.
+// This is intended for resources that must outlive the Server but should
+// still be released within the lame-duck period (e.g. tracers, database
+// pools, caches).
+//
+// Registered closers are called in reverse order of registration (LIFO) just
+// before Run returns. They share whatever remains of the lame-duck period --
+// or, if lame-duck mode never began or its period has already expired, a
+// fresh Period -- and each may be further limited using the StopTimeout
+// Option. Any errors they return are collected in the StopErrors field of the
+// LameDuckError returned by Run.
+//
+// OnStop may be called before or during a call to Run. As with functions
+// passed to Go, closers registered before Run is called are part of the
+// receiver's configuration and are called at the end of every run; those
+// registered while Run is executing are called only at the end of that run.
+func (r *Runner) OnStop(c io.Closer) {
+	r.OnStopFunc(func(context.Context) error { return c.Close() })
+}
+
+// OnStopFunc is like OnStop except that it registers a HookFunction which is
+// passed a Context carrying the closer's deadline.
+func (r *Runner) OnStopFunc(f HookFunction) {
+	if r == nil || f == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closers = append(r.closers, f)
+}
+
+// runClosers calls the receiver's registered closers in LIFO order and
+// returns any errors encountered.
+func (r *Runner) runClosers() []error {
+	r.mu.Lock()
+	closers := make([]HookFunction, len(r.closers))
+	copy(closers, r.closers)
+	r.mu.Unlock()
+
+	if len(closers) == 0 {
+		return nil
+	}
+
+	deadline := r.report().Deadline
+	if deadline.IsZero() || !time.Now().Before(deadline) {
+		deadline = time.Now().Add(r.period)
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	r.logInfo("Running stop closers", "count", len(closers), "deadline", deadline)
+
+	var errs []error
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		start := time.Now()
+		if err := r.runCloser(ctx, closers[i]); err != nil {
+			r.logWarn("Stop closer failed", "closer", i, "error", err)
+			errs = append(errs, err)
+		}
+		r.metrics.ObserveHook("stop", time.Since(start))
+	}
+
+	return errs
+}
+
+// runCloser calls f in its own goroutine and waits for it to return or for
+// ctx (optionally limited by the StopTimeout Option) to be done.
+func (r *Runner) runCloser(ctx context.Context, f HookFunction) error {
+	if r.stopTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.stopTimeout)
+		defer cancel()
+	}
+
+	ch := make(chan error, 1)
+	go func() { ch <- f(ctx) }()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case err := <-ch:
+		return err
+	}
+}
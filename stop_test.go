@@ -0,0 +1,92 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+type stopError struct{ n int }
+
+func (e *stopError) Error() string { return fmt.Sprintf("closer %d failed", e.n) }
+
+func TestOnStop(t *testing.T) {
+	ts := injectSignaller()
+	defer ts.revert()
+
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, nil, nil, nil)
+	svr.shutdown.finish()
+
+	r, err := NewRunner(svr, WithLogger(tl), Period(time.Second), StopTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	errStop := &stopError{2}
+
+	var (
+		mu    sync.Mutex
+		order []int
+	)
+
+	called := func(n int) {
+		mu.Lock()
+		order = append(order, n)
+		mu.Unlock()
+	}
+
+	r.OnStop(closerFunc(func() error { called(1); return nil }))
+	r.OnStopFunc(func(context.Context) error { called(2); return errStop })
+	r.OnStopFunc(func(ctx context.Context) error {
+		called(3)
+		<-ctx.Done()
+		return nil
+	})
+
+	errs := make(chan error, 1)
+	go func() { errs <- r.Run(context.Background()) }()
+
+	<-r.Ready()
+	time.Sleep(10 * time.Millisecond)
+	ts.emit(unix.SIGTERM)
+
+	err = <-errs
+
+	var lde *LameDuckError
+	if !errors.As(err, &lde) {
+		t.Fatalf("Run(ctx) == %#v; wanted *LameDuckError", err)
+	}
+
+	if want := []error{context.DeadlineExceeded, errStop}; !reflect.DeepEqual(lde.StopErrors, want) {
+		t.Errorf("lde.StopErrors == %v; wanted %v", lde.StopErrors, want)
+	}
+
+	for _, want := range []error{errStop, context.DeadlineExceeded} {
+		if !errors.Is(err, want) {
+			t.Errorf("errors.Is(%v, %v) == false; wanted true", err, want)
+		}
+	}
+
+	var sErr *stopError
+	if !errors.As(err, &sErr) || sErr.n != 2 {
+		t.Errorf("errors.As(%v, *stopError) failed", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if want := []int{3, 2, 1}; !reflect.DeepEqual(order, want) {
+		t.Errorf("closers called in order %v; wanted %v", order, want)
+	}
+}
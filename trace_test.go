@@ -0,0 +1,60 @@
+package lameduck
+
+import (
+	"bytes"
+	"context"
+	"runtime/trace"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestRunTrace(t *testing.T) {
+	ts := injectSignaller()
+	defer ts.revert()
+
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("cannot start trace: %v", err)
+	}
+
+	if !trace.IsEnabled() {
+		trace.Stop()
+		t.Fatal("tracing not enabled after trace.Start")
+	}
+
+	tl := &testLogger{t.Logf}
+	svr := newTestServer(tl, nil, nil, nil)
+	svr.shutdown.finish()
+
+	r, err := NewRunner(svr, WithLogger(tl), Period(50*time.Millisecond))
+	if err != nil {
+		trace.Stop()
+		t.Fatalf("cannot create Runner: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- r.Run(context.Background()) }()
+
+	<-r.Ready()
+	time.Sleep(10 * time.Millisecond)
+	ts.emit(unix.SIGTERM)
+
+	err = <-errs
+	trace.Stop()
+
+	if err != nil {
+		t.Fatalf("Run(ctx) == %v; wanted nil", err)
+	}
+
+	// Task, region and log names are recorded in the trace's string table,
+	// each preceded by its (varint) length. Including the length prevents a
+	// match on the tail of a function name (e.g. "(*Runner).waitForSignal")
+	// recorded in a stack trace.
+	for _, name := range []string{"lameduck.Run", "waitForSignal", "Shutdown", "signal", "outcome"} {
+		if !bytes.Contains(buf.Bytes(), append([]byte{byte(len(name))}, name...)) {
+			t.Errorf("trace does not mention %q", name)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package lameduck
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrCloseTimeout is the error returned (in a LameDuckError, having its
+// CloseTimedOut field set to true) when a Server's Close method fails to
+// return within the time allowed by the CloseTimeout Option.
+var ErrCloseTimeout = errors.New("server Close timed out")
+
+// Replaced during testing.
+var osExit = os.Exit
+
+// CloseTimeout returns an Option that limits the time allowed for the
+// Server's Close method (and the closing of any connections tracked by the
+// Runner's ConnRegistry) once the lame-duck period has expired. If Close
+// does not return in time, a stack dump of all goroutines is logged and Run
+// returns a LameDuckError with its CloseTimedOut field set to true -- or, if
+// the ExitOnCloseTimeout Option is also given, the process exits.
+//
+// A zero value (the default) allows Close to block indefinitely.
+func CloseTimeout(d time.Duration) Option {
+	return closeTimeout(d)
+}
+
+type closeTimeout time.Duration
+
+func (d closeTimeout) set(r *Runner) {
+	r.closeTimeout = time.Duration(d)
+}
+
+// ExitOnCloseTimeout returns an Option that causes the process to exit with
+// the given code when the Server's Close method exceeds the time allowed by
+// the CloseTimeout Option. Without a CloseTimeout, this Option has no effect.
+func ExitOnCloseTimeout(code int) Option {
+	return exitOnCloseTimeout(code)
+}
+
+type exitOnCloseTimeout int
+
+func (c exitOnCloseTimeout) set(r *Runner) {
+	code := int(c)
+	r.closeExit = &code
+}
+
+// closeWithTimeout calls f, waiting at most the receiver's configured
+// CloseTimeout for it to return. On timeout, a stack dump is logged and, if
+// so configured, the process exits; otherwise ErrCloseTimeout is returned.
+func (r *Runner) closeWithTimeout(f func() error) error {
+	if r.closeTimeout <= 0 {
+		return f()
+	}
+
+	ch := make(chan error, 1)
+	go func() { ch <- f() }()
+
+	timer := time.NewTimer(r.closeTimeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-ch:
+		return err
+
+	case <-timer.C:
+	}
+
+	dump, n, _ := goroutineDump(nil)
+	r.logError("Server Close timed out", "timeout", r.closeTimeout, "goroutines", n, "dump", dump)
+
+	if r.closeExit != nil {
+		r.logError("Exiting on Close timeout", "code", *r.closeExit)
+		flushLogger(r.logger)
+		osExit(*r.closeExit)
+	}
+
+	return ErrCloseTimeout
+}
@@ -0,0 +1,80 @@
+package lameduck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+type hangingServer struct {
+	*testServer
+	hang chan struct{}
+}
+
+func (hs *hangingServer) Close() error {
+	hs.testServer.Close()
+	<-hs.hang
+	return nil
+}
+
+func TestCloseTimeout(t *testing.T) {
+	cases := map[string]struct {
+		options []Option
+		exit    int // the code passed to osExit; -1 if not called
+	}{
+		"error": {options: []Option{CloseTimeout(10 * time.Millisecond)}, exit: -1},
+		"exit":  {options: []Option{CloseTimeout(10 * time.Millisecond), ExitOnCloseTimeout(42)}, exit: 42},
+	}
+
+	for label, tc := range cases {
+		tc := tc
+		t.Run(label, func(t *testing.T) {
+			ts := injectSignaller()
+			defer ts.revert()
+
+			exitCode := -1
+			defer func() { osExit = osExitOrig }()
+
+			tl := &testLogger{t.Logf}
+			svr := &hangingServer{newTestServer(tl, nil, nil, nil), make(chan struct{})}
+			defer close(svr.hang)
+
+			// Record whether the logger was flushed before osExit was called.
+			var flushed, flushedAtExit bool
+			fl := &FuncLogger{Infof: tl.Infof, Flush: func() { flushed = true }}
+			osExit = func(code int) { exitCode, flushedAtExit = code, flushed }
+
+			options := append([]Option{WithStructuredLogger(fl), Period(20 * time.Millisecond)}, tc.options...)
+
+			r, err := NewRunner(svr, options...)
+			if err != nil {
+				t.Fatalf("cannot create Runner: %v", err)
+			}
+
+			errs := make(chan error, 1)
+			go func() { errs <- r.Run(context.Background()) }()
+
+			<-r.Ready()
+			time.Sleep(10 * time.Millisecond)
+			ts.emit(unix.SIGTERM)
+
+			var lde *LameDuckError
+			if err := <-errs; !errors.As(err, &lde) || !lde.Expired || !lde.CloseTimedOut || lde.Err != ErrCloseTimeout {
+				t.Errorf("Run(ctx) == %#v; wanted &LameDuckError{Expired: true, CloseTimedOut: true, Err: ErrCloseTimeout}", err)
+			}
+
+			if exitCode != tc.exit {
+				t.Errorf("exit code == %d; wanted %d", exitCode, tc.exit)
+			}
+
+			if tc.exit != -1 && !flushedAtExit {
+				t.Error("logger not flushed before exiting")
+			}
+		})
+	}
+}
+
+var osExitOrig = osExit